@@ -0,0 +1,463 @@
+// aggregate functions are evaluated over groups of rows, like the ports of an Aggregator transformation
+// https://docs.informatica.com/data-integration/powercenter/10-4-0/transformation-language-reference/functions/aggregate-functions.html
+
+package expression
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Group is the result of an aggregate expression for the rows with the same values of the group by ports
+type Group struct {
+	Key   Row // the values of the group by ports
+	Value Value
+}
+
+// Aggregate evaluates the program over rows grouped by the values of the groupBy ports, returning a result for each
+// group in the order that the groups were first seen; without group by ports all of the rows are a single group
+// Inside an aggregate function the ports of each row of the group are used, elsewhere the ports of its last row are,
+// as PowerCenter does
+// A nested aggregate, e.g. MAX(COUNT(item)), aggregates the results of the inner function for each group and returns
+// a single Group with an empty Key
+// Aggregate functions skip NULL values and return NULL when there are none to aggregate, except COUNT which returns 0
+func (p *Program) Aggregate(rows []Row, groupBy ...string) (groups []Group, err error) {
+	nested, err := aggregateNesting(p.node, 0)
+	if err != nil {
+		return
+	}
+
+	var keys []string
+	grouped := map[string][]Row{}
+	for _, row := range rows {
+		key := groupKey(row, groupBy)
+		if _, found := grouped[key]; !found {
+			keys = append(keys, key)
+		}
+		grouped[key] = append(grouped[key], row)
+	}
+
+	if nested {
+		var all [][]Row
+		for _, key := range keys {
+			all = append(all, grouped[key])
+		}
+		env := p.evaluator.newEnvironment(Row{})
+		env.groups = all
+		if len(rows) > 0 {
+			env.row = rows[len(rows)-1]
+		}
+		var value Value
+		if value, err = evaluateNode(p.node, env); err != nil {
+			return
+		}
+		groups = []Group{{Key: Row{}, Value: value}}
+		return
+	}
+
+	for _, key := range keys {
+		group := grouped[key]
+		last := group[len(group)-1]
+		env := p.evaluator.newEnvironment(last)
+		env.group = group
+		var value Value
+		if value, err = evaluateNode(p.node, env); err != nil {
+			return
+		}
+		groupRow := Row{}
+		for _, port := range groupBy {
+			groupRow[port] = last[port]
+		}
+		groups = append(groups, Group{Key: groupRow, Value: value})
+	}
+
+	return
+}
+
+// groupKey identifies the group of a row by the types and values of its group by ports; NULLs are a group of their own
+func groupKey(row Row, groupBy []string) string {
+	var b strings.Builder
+	for _, port := range groupBy {
+		v := row[port]
+		if v.IsNull() {
+			b.WriteString("NULL")
+		} else {
+			fmt.Fprintf(&b, "%d:%s", v.typ, v.String())
+		}
+		b.WriteByte(0)
+	}
+
+	return b.String()
+}
+
+// isAggregate checks if a node is a call to an aggregate function
+func isAggregate(node Node) bool {
+	s, found := functions[node.Exp]
+	return found && s.Aggregate
+}
+
+// aggregateNesting checks how aggregate functions are nested in the node and returns true if they are nested one in
+// another; aggregates can only be nested one level deep and either all or none of the outer ones must be nested
+func aggregateNesting(node Node, depth int) (nested bool, err error) {
+	if isAggregate(node) {
+		if depth == 1 {
+			nested = true
+		} else if depth > 1 {
+			err = fmt.Errorf("the aggregate function %s is nested more than one level deep", node.Exp)
+			return
+		}
+		for _, arg := range node.Args {
+			var inner bool
+			if inner, err = aggregateNesting(arg, depth+1); err != nil {
+				return
+			}
+			nested = nested || inner
+		}
+		return
+	}
+
+	var sawNested, sawFlat bool
+	for _, arg := range node.Args {
+		var inner bool
+		if inner, err = aggregateNesting(arg, depth); err != nil {
+			return
+		}
+		if inner {
+			sawNested = true
+		} else if depth == 0 && containsAggregate(arg) {
+			sawFlat = true
+		}
+	}
+	if sawNested && sawFlat {
+		err = fmt.Errorf("nested and non-nested aggregate functions can't be used in the same expression")
+		return
+	}
+	nested = sawNested
+
+	return
+}
+
+// containsAggregate checks if there is a call to an aggregate function in the node
+func containsAggregate(node Node) bool {
+	if isAggregate(node) {
+		return true
+	}
+	for _, arg := range node.Args {
+		if containsAggregate(arg) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// aggregateValues evaluates the first argument of an aggregate function for each row of the group, or for each group
+// of a nested aggregate, skipping those where the filter_condition at filterArg isn't TRUE
+func aggregateValues(fn string, env *environment, args []Node, filterArg int) (values []Value, err error) {
+	var envs []*environment
+	switch {
+	case env.groups != nil:
+		for _, group := range env.groups {
+			envs = append(envs, &environment{evaluator: env.evaluator, row: group[len(group)-1], group: group, now: env.now})
+		}
+	case env.group != nil:
+		for _, row := range env.group {
+			envs = append(envs, &environment{evaluator: env.evaluator, row: row, now: env.now})
+		}
+	default:
+		err = fmt.Errorf("%s is an aggregate function so it can only be evaluated with Program.Aggregate", fn)
+		return
+	}
+
+	for _, rowEnv := range envs {
+		if len(args) > filterArg {
+			var filter Value
+			if filter, err = evaluateNode(args[filterArg], rowEnv); err != nil {
+				return
+			}
+			if filter.IsNull() {
+				continue
+			}
+			var include bool
+			if include, err = isTrue(fn, filter); err != nil || !include {
+				if err != nil {
+					return
+				}
+				continue
+			}
+		}
+
+		var v Value
+		if v, err = evaluateNode(args[0], rowEnv); err != nil {
+			return
+		}
+		values = append(values, v)
+	}
+
+	return
+}
+
+// nonNull returns the values which aren't NULL
+func nonNull(values []Value) (result []Value) {
+	for _, v := range values {
+		if !v.IsNull() {
+			result = append(result, v)
+		}
+	}
+
+	return
+}
+
+// aggregateNumbers returns the values of the first argument which aren't NULL, as numbers
+func aggregateNumbers(fn string, env *environment, args []Node, filterArg int) (numbers []Value, err error) {
+	values, err := aggregateValues(fn, env, args, filterArg)
+	if err != nil {
+		return
+	}
+
+	for _, v := range nonNull(values) {
+		var n Value
+		if n, err = toNumeric(fn, v); err != nil {
+			return
+		}
+		numbers = append(numbers, n)
+	}
+
+	return
+}
+
+// count returns the number of values which aren't NULL; COUNT(*) counts every row
+func count(env *environment, args ...Node) (result Value, err error) {
+	values, err := aggregateValues("COUNT", env, args, 1)
+	if err != nil {
+		return
+	}
+	result = NewInteger(int32(len(nonNull(values))))
+
+	return
+}
+
+// sum adds the values with the same arithmetic as +, so integers which overflow become bigints and then doubles
+func sum(env *environment, args ...Node) (result Value, err error) {
+	numbers, err := aggregateNumbers("SUM", env, args, 1)
+	if err != nil || len(numbers) == 0 {
+		result = NewNull(resultType(args[0]))
+		return
+	}
+
+	result = numbers[0]
+	for _, n := range numbers[1:] {
+		if result, err = add(result, n); err != nil {
+			return
+		}
+	}
+	result = env.evaluator.precision(result)
+
+	return
+}
+
+// avg returns the mean of the values
+func avg(env *environment, args ...Node) (result Value, err error) {
+	numbers, err := aggregateNumbers("AVG", env, args, 1)
+	if err != nil || len(numbers) == 0 {
+		result = NewNull(TypeDouble)
+		return
+	}
+
+	total := numbers[0]
+	for _, n := range numbers[1:] {
+		if total, err = add(total, n); err != nil {
+			return
+		}
+	}
+	if result, err = divide(total, NewInteger(int32(len(numbers)))); err != nil {
+		return
+	}
+	result = env.evaluator.precision(result)
+
+	return
+}
+
+func maxAggregate(env *environment, args ...Node) (result Value, err error) {
+	return extreme("MAX", env, args, 1)
+}
+
+func minAggregate(env *environment, args ...Node) (result Value, err error) {
+	return extreme("MIN", env, args, -1)
+}
+
+// extreme returns the value that compares greatest when sign is 1 or least when it is -1, of any datatype
+func extreme(fn string, env *environment, args []Node, sign int) (result Value, err error) {
+	values, err := aggregateValues(fn, env, args, 1)
+	if err != nil {
+		return
+	}
+
+	values = nonNull(values)
+	if len(values) == 0 {
+		result = NewNull(resultType(args[0]))
+		return
+	}
+	result = values[0]
+	for _, v := range values[1:] {
+		var c int
+		if c, err = compare(fn, v, result); err != nil {
+			return
+		}
+		if c*sign > 0 {
+			result = v
+		}
+	}
+
+	return
+}
+
+// first returns the first value which isn't NULL
+func first(env *environment, args ...Node) (result Value, err error) {
+	values, err := aggregateValues("FIRST", env, args, 1)
+	if err != nil {
+		return
+	}
+
+	if values = nonNull(values); len(values) == 0 {
+		result = NewNull(resultType(args[0]))
+	} else {
+		result = values[0]
+	}
+
+	return
+}
+
+// last returns the last value which isn't NULL
+func last(env *environment, args ...Node) (result Value, err error) {
+	values, err := aggregateValues("LAST", env, args, 1)
+	if err != nil {
+		return
+	}
+
+	if values = nonNull(values); len(values) == 0 {
+		result = NewNull(resultType(args[0]))
+	} else {
+		result = values[len(values)-1]
+	}
+
+	return
+}
+
+// sortedFloats returns the values of an aggregate function's first argument which aren't NULL, in ascending order
+func sortedFloats(fn string, env *environment, args []Node, filterArg int) (floats []float64, err error) {
+	numbers, err := aggregateNumbers(fn, env, args, filterArg)
+	if err != nil {
+		return
+	}
+
+	for _, n := range numbers {
+		floats = append(floats, n.Float())
+	}
+	sort.Float64s(floats)
+
+	return
+}
+
+// median returns the middle value, or the mean of the two middle values when there is an even number of them
+func median(env *environment, args ...Node) (result Value, err error) {
+	floats, err := sortedFloats("MEDIAN", env, args, 1)
+	if err != nil || len(floats) == 0 {
+		result = NewNull(TypeDouble)
+		return
+	}
+
+	mid := len(floats) / 2
+	if len(floats)%2 == 1 {
+		result = NewDouble(floats[mid])
+	} else {
+		result = NewDouble((floats[mid-1] + floats[mid]) / 2)
+	}
+
+	return
+}
+
+// percentile returns the value at a percentile from 0 to 100: with n values, i = percentile * n / 100 and the result
+// is the mean of the ith and i+1th values when i is a whole number, otherwise the value at i rounded up
+func percentile(env *environment, args ...Node) (result Value, err error) {
+	p, err := evaluateNode(args[1], env)
+	if err != nil {
+		return
+	}
+	if p.IsNull() {
+		result = NewNull(TypeDouble)
+		return
+	}
+	if p, err = toNumeric("PERCENTILE", p); err != nil {
+		return
+	}
+	if p.Float() < 0 || p.Float() > 100 {
+		err = &RowError{Function: "PERCENTILE", Message: fmt.Sprintf("the percentile %s is not between 0 and 100", p)}
+		return
+	}
+
+	floats, err := sortedFloats("PERCENTILE", env, args, 2)
+	if err != nil || len(floats) == 0 {
+		result = NewNull(TypeDouble)
+		return
+	}
+
+	i := p.Float() * float64(len(floats)) / 100
+	switch {
+	case i < 1:
+		result = NewDouble(floats[0])
+	case i >= float64(len(floats)):
+		result = NewDouble(floats[len(floats)-1])
+	case i == math.Trunc(i):
+		result = NewDouble((floats[int(i)-1] + floats[int(i)]) / 2)
+	default:
+		result = NewDouble(floats[int(math.Ceil(i))-1])
+	}
+
+	return
+}
+
+// variance returns the sample variance of the values, which is 0 for a single value
+func variance(env *environment, args ...Node) (result Value, err error) {
+	floats, err := sortedFloats("VARIANCE", env, args, 1)
+	if err != nil || len(floats) == 0 {
+		result = NewNull(TypeDouble)
+		return
+	}
+	result = NewDouble(sampleVariance(floats))
+
+	return
+}
+
+// stddev returns the sample standard deviation of the values, which is 0 for a single value
+func stddev(env *environment, args ...Node) (result Value, err error) {
+	floats, err := sortedFloats("STDDEV", env, args, 1)
+	if err != nil || len(floats) == 0 {
+		result = NewNull(TypeDouble)
+		return
+	}
+	result = NewDouble(math.Sqrt(sampleVariance(floats)))
+
+	return
+}
+
+func sampleVariance(floats []float64) float64 {
+	if len(floats) < 2 {
+		return 0
+	}
+
+	mean := 0.0
+	for _, f := range floats {
+		mean += f
+	}
+	mean /= float64(len(floats))
+
+	squares := 0.0
+	for _, f := range floats {
+		squares += (f - mean) * (f - mean)
+	}
+
+	return squares / float64(len(floats)-1)
+}
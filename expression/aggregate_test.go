@@ -0,0 +1,167 @@
+package expression
+
+import (
+	"testing"
+)
+
+var aggregateSchema = []Declaration{
+	{Name: "in_STORE", Type: TypeString},
+	{Name: "in_ITEM", Type: TypeString},
+	{Name: "in_QTY", Type: TypeInteger},
+	{Name: "in_PRICE", Type: TypeDouble},
+}
+
+var aggregateRows = []Row{
+	{"in_STORE": NewString("a"), "in_ITEM": NewString("pen"), "in_QTY": NewInteger(4), "in_PRICE": NewDouble(1.5)},
+	{"in_STORE": NewString("b"), "in_ITEM": NewString("ink"), "in_QTY": NewInteger(1), "in_PRICE": NewDouble(8)},
+	{"in_STORE": NewString("a"), "in_ITEM": NewString("pad"), "in_QTY": NewInteger(2), "in_PRICE": NewDouble(3)},
+	{"in_STORE": NewString("a"), "in_ITEM": NewString("ink"), "in_QTY": Value{}, "in_PRICE": NewDouble(8)},
+	{"in_STORE": NewString("b"), "in_ITEM": NewString("pen"), "in_QTY": NewInteger(6), "in_PRICE": NewDouble(1.5)},
+	{"in_STORE": Value{}, "in_ITEM": NewString("pad"), "in_QTY": NewInteger(3), "in_PRICE": NewDouble(3)},
+}
+
+// groupsString formats the groups as key=value pairs separated by semicolons, leaving out the empty key of a nested
+// aggregate
+func groupsString(groups []Group, groupBy []string) string {
+	s := ""
+	for i, g := range groups {
+		if i > 0 {
+			s += "; "
+		}
+		for _, port := range groupBy {
+			if v, ok := g.Key[port]; ok {
+				s += v.String() + "="
+			}
+		}
+		s += g.Value.String()
+	}
+
+	return s
+}
+
+func TestAggregate(t *testing.T) {
+	testCases := []struct {
+		input   string
+		groupBy []string
+		expect  string
+	}{
+		{`COUNT(in_QTY)`, nil, `5`},
+		{`COUNT(*)`, nil, `6`},
+		{`COUNT(*, in_QTY > 2)`, nil, `3`},
+		{`COUNT(*, in_QTY > 2)`, []string{"in_STORE"}, `a=1; b=1; NULL=1`},
+		{`COUNT(in_QTY)`, []string{"in_STORE"}, `a=2; b=2; NULL=1`},
+		{`COUNT(*)`, []string{"in_STORE"}, `a=3; b=2; NULL=1`},
+		{`COUNT(in_ITEM, in_QTY > 2)`, nil, `3`},
+		{`SUM(in_QTY)`, []string{"in_STORE"}, `a=6; b=7; NULL=3`},
+		{`SUM(in_QTY * in_PRICE)`, nil, `38`},
+		{`SUM(in_QTY, in_ITEM = 'pen')`, nil, `10`},
+		{`SUM(in_QTY, in_ITEM = 'none')`, nil, `NULL`},
+		{`AVG(in_QTY)`, nil, `3.2`},
+		{`AVG(in_QTY)`, []string{"in_STORE"}, `a=3; b=3.5; NULL=3`},
+		{`MAX(in_QTY)`, nil, `6`},
+		{`MIN(in_QTY)`, []string{"in_STORE"}, `a=2; b=1; NULL=3`},
+		{`MAX(in_ITEM)`, nil, `pen`},
+		{`MIN(in_ITEM, in_QTY > 1)`, nil, `pad`},
+		{`MEDIAN(in_QTY)`, nil, `3`},
+		{`MEDIAN(in_PRICE)`, nil, `3`},
+		{`MEDIAN(in_QTY)`, []string{"in_STORE"}, `a=3; b=3.5; NULL=3`},
+		{`PERCENTILE(in_QTY, 50)`, nil, `3`},
+		{`PERCENTILE(in_QTY, 40)`, nil, `2.5`},
+		{`PERCENTILE(in_QTY, 0)`, nil, `1`},
+		{`PERCENTILE(in_QTY, 100)`, nil, `6`},
+		{`PERCENTILE(in_QTY, 90, in_QTY < 6)`, nil, `4`},
+		{`VARIANCE(in_QTY)`, nil, `3.7`},
+		{`STDDEV(in_QTY)`, []string{"in_STORE"}, `a=1.4142135623731; b=3.53553390593274; NULL=0`},
+		{`VARIANCE(in_QTY, in_QTY > 10)`, nil, `NULL`},
+		{`FIRST(in_QTY)`, []string{"in_STORE"}, `a=4; b=1; NULL=3`},
+		{`LAST(in_QTY)`, []string{"in_STORE"}, `a=2; b=6; NULL=3`},
+		{`LAST(in_ITEM, in_QTY < 3)`, nil, `pad`},
+		{`SUM(in_QTY)`, []string{"in_STORE", "in_ITEM"}, `a=pen=4; b=ink=1; a=pad=2; a=ink=NULL; b=pen=6; NULL=pad=3`},
+		// outside of an aggregate function the ports of the last row of the group are used
+		{`CONCAT(in_ITEM, TO_CHAR(SUM(in_QTY)))`, []string{"in_STORE"}, `a=ink6; b=pen7; NULL=pad3`},
+		{`SUM(in_QTY) / COUNT(*)`, nil, `2.66666666666667`},
+		{`MAX(COUNT(in_ITEM))`, []string{"in_STORE"}, `3`},
+		{`AVG(SUM(in_QTY))`, []string{"in_STORE"}, `5.33333333333333`},
+		{`MIN(SUM(in_QTY, in_ITEM <> 'pen'))`, []string{"in_STORE"}, `1`},
+	}
+
+	for _, tc := range testCases {
+		program, err := Compile(tc.input, aggregateSchema)
+		if err != nil {
+			t.Errorf("Input: %s\nUnexpected error: %v", tc.input, err)
+			continue
+		}
+		groups, err := program.Aggregate(aggregateRows, tc.groupBy...)
+		if err != nil {
+			t.Errorf("Input: %s\nUnexpected error: %v", tc.input, err)
+			continue
+		}
+		if result := groupsString(groups, tc.groupBy); result != tc.expect {
+			t.Errorf("Input: %s\nExpected: `%s`, got `%s`", tc.input, tc.expect, result)
+		}
+	}
+}
+
+func TestAggregateEvaluatesOnce(t *testing.T) {
+	m := newMappingVariables(t)
+	program, err := newEvaluator(t, Options{MappingVariables: m}).Compile("AVG(SETCOUNTVARIABLE($$ROWS))", aggregateSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the argument is evaluated once for each row, so a function with side effects only runs once
+	groups, err := program.Aggregate(aggregateRows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result := groupsString(groups, nil); result != "3.5" {
+		t.Errorf("Expected: `3.5`, got `%s`", result)
+	}
+	if result := m.Current("$$ROWS").String(); result != "6" {
+		t.Errorf("Expected $$ROWS to be `6`, got `%s`", result)
+	}
+}
+
+func TestAggregateNoRows(t *testing.T) {
+	program, err := Compile("SUM(in_QTY)", aggregateSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	groups, err := program.Aggregate(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(groups) != 0 {
+		t.Errorf("Expected no groups, got %d", len(groups))
+	}
+}
+
+func TestAggregateErrors(t *testing.T) {
+	testCases := []string{
+		`MAX(SUM(COUNT(in_QTY)))`,
+		`MAX(COUNT(in_ITEM)) + SUM(in_QTY)`,
+		`PERCENTILE(in_QTY, 101)`,
+		`SUM(in_ITEM)`,
+	}
+
+	for _, input := range testCases {
+		program, err := Compile(input, aggregateSchema)
+		if err != nil {
+			t.Errorf("Input: %s\nUnexpected error: %v", input, err)
+			continue
+		}
+		if _, err := program.Aggregate(aggregateRows, "in_STORE"); err == nil {
+			t.Errorf("Input: %s\nExpected an error", input)
+		}
+	}
+
+	// aggregate functions need the rows of a group
+	program, err := Compile("SUM(in_QTY)", aggregateSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := program.Run(aggregateRows[0]); err == nil {
+		t.Error("Expected an error running an aggregate function on a single row")
+	}
+}
@@ -0,0 +1,97 @@
+// binary functions
+// Binaries are compared and measured by their bytes; unlike CONCAT for strings, a NULL argument makes the result NULL
+
+package expression
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// toBinary returns the bytes of a binary argument; binaries aren't converted from other datatypes implicitly
+func toBinary(fn string, v Value) (b []byte, err error) {
+	if v.typ != TypeBinary {
+		err = fmt.Errorf("%s expected a binary but got %s", fn, v.typ)
+		return
+	}
+	b = v.b
+
+	return
+}
+
+// binaryCompare returns 0 if the binaries are equal, -1 if the first sorts before the second byte by byte (a prefix
+// sorting first) and 1 if it sorts after
+func binaryCompare(args ...Value) (result Value, err error) {
+	if anyNull(args) {
+		result = NewNull(TypeInteger)
+		return
+	}
+
+	a, err := toBinary("BINARY_COMPARE", args[0])
+	if err != nil {
+		return
+	}
+	b, err := toBinary("BINARY_COMPARE", args[1])
+	if err != nil {
+		return
+	}
+	result = NewInteger(int32(bytes.Compare(a, b)))
+
+	return
+}
+
+// binaryConcat returns the bytes of the first binary followed by those of the second
+func binaryConcat(args ...Value) (result Value, err error) {
+	if anyNull(args) {
+		result = NewNull(TypeBinary)
+		return
+	}
+
+	a, err := toBinary("BINARY_CONCAT", args[0])
+	if err != nil {
+		return
+	}
+	b, err := toBinary("BINARY_CONCAT", args[1])
+	if err != nil {
+		return
+	}
+	result = NewBinary(append(append(make([]byte, 0, len(a)+len(b)), a...), b...))
+
+	return
+}
+
+// binaryLength returns the number of bytes in the binary
+func binaryLength(args ...Value) (result Value, err error) {
+	if args[0].IsNull() {
+		result = NewNull(TypeInteger)
+		return
+	}
+
+	b, err := toBinary("BINARY_LENGTH", args[0])
+	if err != nil {
+		return
+	}
+	result = NewInteger(int32(len(b)))
+
+	return
+}
+
+// binarySection returns length bytes of the binary from start, with the same rules for start and length as SUBSTR
+func binarySection(args ...Value) (result Value, err error) {
+	if anyNull(args) {
+		result = NewNull(TypeBinary)
+		return
+	}
+
+	b, err := toBinary("BINARY_SECTION", args[0])
+	if err != nil {
+		return
+	}
+	from, to, err := section("BINARY_SECTION", len(b), args[1:])
+	if err != nil {
+		return
+	}
+	result = NewBinary(append([]byte(nil), b[from:to]...))
+
+	return
+}
@@ -0,0 +1,53 @@
+package expression
+
+import (
+	"testing"
+)
+
+func TestBinaryFunctions(t *testing.T) {
+	vars := []Variable{
+		{N: "in_A", T: "BINARY", V: "48656C6C6F"},
+		{N: "in_B", T: "BINARY", V: "0x2c20776f726c64"},
+		{N: "in_EMPTY", T: "BINARY", V: ""},
+		{N: "in_ZERO", T: "BINARY", V: "00"},
+	}
+	testCases := []stringTestCase{
+		{`in_A`, `48656C6C6F`},
+		{`BINARY_COMPARE(in_A, in_A)`, `0`},
+		{`BINARY_COMPARE(in_A, in_B)`, `1`},
+		{`BINARY_COMPARE(in_B, in_A)`, `-1`},
+		{`BINARY_COMPARE(in_EMPTY, in_A)`, `-1`},
+		{`BINARY_COMPARE(in_A, NULL)`, `NULL`},
+		{`BINARY_CONCAT(in_A, in_B)`, `48656C6C6F2C20776F726C64`},
+		{`BINARY_CONCAT(in_EMPTY, in_A)`, `48656C6C6F`},
+		{`BINARY_CONCAT(in_A, NULL)`, `NULL`},
+		{`BINARY_LENGTH(in_A)`, `5`},
+		{`BINARY_LENGTH(in_EMPTY)`, `0`},
+		{`BINARY_LENGTH(in_ZERO)`, `1`},
+		{`BINARY_LENGTH(NULL)`, `NULL`},
+		{`BINARY_SECTION(in_A, 2, 3)`, `656C6C`},
+		{`BINARY_SECTION(in_A, 0, 1)`, `48`},
+		{`BINARY_SECTION(in_A, -2)`, `6C6F`},
+		{`BINARY_SECTION(in_A, 4, 10)`, `6C6F`},
+		{`BINARY_SECTION(in_A, 6)`, ``},
+		{`BINARY_SECTION(in_A, -6, 2)`, ``},
+		{`BINARY_SECTION(in_A, 1, 0)`, ``},
+		{`BINARY_SECTION(in_A, 1, NULL)`, `NULL`},
+		{`ENC_BASE64(BINARY_SECTION(in_A, 1, 2))`, `SGU=`},
+	}
+
+	for _, tc := range testCases {
+		result, err := Evaluate(tc.input, vars)
+		if err != nil {
+			t.Errorf("Input: %s\nUnexpected error: %v", tc.input, err)
+			continue
+		}
+		if result.String() != tc.expect {
+			t.Errorf("Input: %s\nExpected: `%s`, got `%s`", tc.input, tc.expect, result.String())
+		}
+	}
+
+	if _, err := Evaluate(`BINARY_LENGTH(in_A)`, []Variable{{N: "in_A", T: "BINARY", V: "xyz"}}); err == nil {
+		t.Error("Expected an error for a BINARY variable which isn't hex")
+	}
+}
@@ -0,0 +1,107 @@
+// code pages convert strings to and from the bytes that hashing, encoding and compression functions work on
+
+package expression
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// codePage converts between strings and bytes in one character set
+// Characters that the code page can't represent are written as '?', as PowerCenter does
+type codePage struct {
+	encode func(s string) []byte
+	decode func(b []byte) string
+}
+
+// DefaultCodePage is the code page strings are converted with when Options.CodePage isn't set
+const DefaultCodePage = "UTF-8"
+
+// codePages are the supported code pages by their PowerCenter names and common aliases
+var codePages = map[string]codePage{
+	"UTF-8":      {encode: func(s string) []byte { return []byte(s) }, decode: func(b []byte) string { return string(b) }},
+	"ISO-8859-1": singleByte(0xFF, nil),
+	"LATIN1":     singleByte(0xFF, nil),
+	"MS1252":     singleByte(0xFF, ms1252),
+	"CP1252":     singleByte(0xFF, ms1252),
+	"US-ASCII":   singleByte(0x7F, nil),
+	"ASCII":      singleByte(0x7F, nil),
+}
+
+// ms1252 are the characters of Windows-1252 which differ from ISO-8859-1; 0x81, 0x8D, 0x8F, 0x90 and 0x9D are unused
+// and are kept as the control characters with the same code
+var ms1252 = map[byte]rune{
+	0x80: '€', 0x82: '‚', 0x83: 'ƒ', 0x84: '„', 0x85: '…', 0x86: '†', 0x87: '‡', 0x88: 'ˆ', 0x89: '‰', 0x8A: 'Š',
+	0x8B: '‹', 0x8C: 'Œ', 0x8E: 'Ž', 0x91: '‘', 0x92: '’', 0x93: '“', 0x94: '”', 0x95: '•', 0x96: '–', 0x97: '—',
+	0x98: '˜', 0x99: '™', 0x9A: 'š', 0x9B: '›', 0x9C: 'œ', 0x9E: 'ž', 0x9F: 'Ÿ',
+}
+
+// singleByte returns a code page with one byte per character, where bytes up to max are the Unicode code point with
+// the same value unless they are in special
+func singleByte(max byte, special map[byte]rune) codePage {
+	toByte := map[rune]byte{}
+	for b, r := range special {
+		toByte[r] = b
+	}
+
+	return codePage{
+		encode: func(s string) []byte {
+			out := make([]byte, 0, len(s))
+			for _, r := range s {
+				if b, ok := toByte[r]; ok {
+					out = append(out, b)
+				} else if _, isSpecial := special[byte(r)]; r <= rune(max) && !isSpecial {
+					out = append(out, byte(r))
+				} else {
+					out = append(out, '?')
+				}
+			}
+			return out
+		},
+		decode: func(b []byte) string {
+			var s strings.Builder
+			for _, c := range b {
+				if r, ok := special[c]; ok {
+					s.WriteRune(r)
+				} else if c <= max {
+					s.WriteRune(rune(c))
+				} else {
+					s.WriteRune(utf8.RuneError)
+				}
+			}
+			return s.String()
+		},
+	}
+}
+
+// codePage returns the code page of the evaluator's options
+func (e *Evaluator) codePage() (cp codePage, err error) {
+	name := strings.ToUpper(strings.TrimSpace(e.options.CodePage))
+	if name == "" {
+		name = DefaultCodePage
+	}
+
+	cp, ok := codePages[name]
+	if !ok {
+		err = fmt.Errorf("the code page '%s' isn't supported", e.options.CodePage)
+	}
+
+	return
+}
+
+// toBytes returns the bytes of a binary, or of any other value as a string in the evaluator's code page
+func toBytes(env *environment, v Value) (b []byte, err error) {
+	if v.typ == TypeBinary {
+		b = v.b
+		return
+	}
+
+	cp, err := env.evaluator.codePage()
+	if err != nil {
+		return
+	}
+	b = cp.encode(toText(v))
+
+	return
+}
@@ -0,0 +1,211 @@
+// conditional functions evaluate their arguments lazily so that only the chosen branch is evaluated
+
+package expression
+
+import (
+	"math"
+	"strings"
+)
+
+// iif returns value1 if the condition is TRUE, otherwise value2
+// When value2 is omitted a FALSE condition returns 0 for numbers, an empty string for strings, and NULL otherwise
+func iif(env *environment, args ...Node) (result Value, err error) {
+	condition, err := evaluateNode(args[0], env)
+	if err != nil {
+		return
+	}
+
+	b := false
+	if !condition.IsNull() {
+		b, err = isTrue("IIF", condition)
+		if err != nil {
+			return
+		}
+	}
+
+	switch {
+	case b:
+		result, err = evaluateNode(args[1], env)
+	case len(args) == 3:
+		result, err = evaluateNode(args[2], env)
+	default:
+		result = defaultValue(args[1])
+	}
+
+	return
+}
+
+// decode searches for the first search value which matches the value and returns its result
+// Unlike the = operator, a NULL value matches a NULL search value
+// If nothing matches then the optional default is returned, otherwise NULL
+func decode(env *environment, args ...Node) (result Value, err error) {
+	value, err := evaluateNode(args[0], env)
+	if err != nil {
+		return
+	}
+
+	pos := 1
+	for ; pos+1 < len(args); pos += 2 {
+		search, sErr := evaluateNode(args[pos], env)
+		if sErr != nil {
+			err = sErr
+			return
+		}
+
+		match := false
+		switch {
+		case value.IsNull() || search.IsNull():
+			match = value.IsNull() && search.IsNull()
+		default:
+			c, cErr := compare("DECODE", value, search)
+			if cErr != nil {
+				err = cErr
+				return
+			}
+			match = c == 0
+		}
+
+		if match {
+			result, err = evaluateNode(args[pos+1], env)
+			return
+		}
+	}
+
+	// an odd arg left over is the default
+	if pos < len(args) {
+		result, err = evaluateNode(args[pos], env)
+		return
+	}
+
+	result = NewNull(resultType(args[2]))
+
+	return
+}
+
+// choose returns the value at the (1 based) index, or NULL if the index is NULL or out of range
+func choose(env *environment, args ...Node) (result Value, err error) {
+	index, err := evaluateNode(args[0], env)
+	if err != nil {
+		return
+	}
+
+	if index.IsNull() {
+		result = NewNull(resultType(args[1]))
+		return
+	}
+
+	n, err := toNumeric("CHOOSE", index)
+	if err != nil {
+		return
+	}
+
+	i := int(math.Trunc(n.Float()))
+	if i < 1 || i >= len(args) {
+		result = NewNull(resultType(args[1]))
+		return
+	}
+
+	result, err = evaluateNode(args[i], env)
+
+	return
+}
+
+// in returns TRUE (1) if the value matches any of the values in the list, FALSE (0) if not, and NULL if the value is NULL
+// When searching for a string, a trailing number is the CaseFlag: 0 or NULL is case insensitive, anything else is case sensitive
+func in(env *environment, args ...Node) (result Value, err error) {
+	value, err := evaluateNode(args[0], env)
+	if err != nil {
+		return
+	}
+
+	if value.IsNull() {
+		result = NewNull(TypeInteger)
+		return
+	}
+
+	list := args[1:]
+	caseSensitive := true
+	if value.typ == TypeString {
+		list, caseSensitive, err = caseFlag("IN", env, list)
+		if err != nil {
+			return
+		}
+	}
+
+	for _, arg := range list {
+		item, iErr := evaluateNode(arg, env)
+		if iErr != nil {
+			err = iErr
+			return
+		}
+
+		if item.IsNull() {
+			continue
+		}
+
+		if !caseSensitive && item.typ == TypeString {
+			if strings.EqualFold(value.s, item.s) {
+				result = boolValue(true)
+				return
+			}
+			continue
+		}
+
+		c, cErr := compare("IN", value, item)
+		if cErr != nil {
+			err = cErr
+			return
+		}
+		if c == 0 {
+			result = boolValue(true)
+			return
+		}
+	}
+
+	result = boolValue(false)
+
+	return
+}
+
+// caseFlag splits the optional CaseFlag from the end of a list of strings to search, which is recognized by being
+// a number; without it the search is case sensitive
+func caseFlag(fn string, env *environment, list []Node) (rest []Node, caseSensitive bool, err error) {
+	rest, caseSensitive = list, true
+	if t, ok := staticType(list[len(list)-1]); len(list) < 2 || !ok || !t.IsNumeric() {
+		return
+	}
+
+	flag, err := evaluateNode(list[len(list)-1], env)
+	if err != nil {
+		return
+	}
+	rest = list[:len(list)-1]
+	caseSensitive, err = caseFlagValue(fn, flag)
+
+	return
+}
+
+// caseFlagValue converts the value of a CaseFlag argument: 0 or NULL is case insensitive, anything else is case
+// sensitive
+func caseFlagValue(fn string, flag Value) (caseSensitive bool, err error) {
+	if flag.IsNull() {
+		return
+	}
+
+	return isTrue(fn, flag)
+}
+
+// defaultValue is the value IIF returns for a FALSE condition when value2 is omitted
+func defaultValue(node Node) (result Value) {
+	t := resultType(node)
+	switch {
+	case t == TypeString:
+		result = NewString("")
+	case t.IsNumeric():
+		result, _ = convertNumber(NewInteger(0), t)
+	default:
+		result = NewNull(t)
+	}
+
+	return
+}
@@ -0,0 +1,139 @@
+package expression
+
+import "testing"
+
+func TestIIF(t *testing.T) {
+	testCases := []struct {
+		input  string
+		expect string
+	}{
+		{`IIF(1 < 2, $$Y, $$N)`, `YES`},
+		{`IIF(1 > 2, $$Y, $$N)`, `NO`},
+		{`IIF(NULL, 'a', 'b')`, `b`},
+		{`IIF(0.5, 'a', 'b')`, `a`},
+		{`IIF(FALSE, 'a')`, ``},
+		{`IIF(FALSE, 1)`, `0`},
+		{`IIF(FALSE, 1 + 2)`, `0`},
+		{`IIF(FALSE, NULL)`, `NULL`},
+		{`IIF(TRUE, 1, ERROR('not evaluated'))`, `1`},
+		{`IIF(FALSE, ABORT('not evaluated'), 2)`, `2`},
+		{`IIF(1 = 1, IIF(2 = 3, 'a', 'b'), 'c')`, `b`},
+	}
+
+	vars := []Variable{
+		{"$$Y", "STRING", "YES"},
+		{"$$N", "STRING", "NO"},
+	}
+
+	for _, tc := range testCases {
+		result, err := Evaluate(tc.input, vars)
+		if err != nil {
+			t.Error(err)
+		}
+
+		if result.String() != tc.expect {
+			t.Errorf("Input: %s\nExpected: `%s`, got `%s`", tc.input, tc.expect, result.String())
+		}
+	}
+}
+
+func TestDECODE(t *testing.T) {
+	testCases := []struct {
+		input  string
+		expect string
+	}{
+		{`DECODE(2, 1, 'one', 2, 'two', 'other')`, `two`},
+		{`DECODE(3, 1, 'one', 2, 'two', 'other')`, `other`},
+		{`DECODE(3, 1, 'one', 2, 'two')`, `NULL`},
+		{`DECODE(NULL, 1, 'one', NULL, 'null')`, `null`},
+		{`DECODE(1, NULL, 'null', 1, 'one')`, `one`},
+		{`DECODE('a', 'A', 1, 'a', 2)`, `2`},
+		{`DECODE(TRUE, 1 > 2, 'first', 2 > 1, 'second')`, `second`},
+		{`DECODE(1, 1, 'one', 2, ERROR('not evaluated'))`, `one`},
+		{`DECODE(1, 1, 'one', ERROR('not evaluated'), 'two')`, `one`},
+	}
+
+	vars := make([]Variable, 0)
+
+	for _, tc := range testCases {
+		result, err := Evaluate(tc.input, vars)
+		if err != nil {
+			t.Error(err)
+		}
+
+		if result.String() != tc.expect {
+			t.Errorf("Input: %s\nExpected: `%s`, got `%s`", tc.input, tc.expect, result.String())
+		}
+	}
+}
+
+func TestCHOOSE(t *testing.T) {
+	testCases := []struct {
+		input  string
+		expect string
+	}{
+		{`CHOOSE(2, 'a', 'b', 'c')`, `b`},
+		{`CHOOSE(1, 'a', 'b', 'c')`, `a`},
+		{`CHOOSE(3.7, 'a', 'b', 'c')`, `c`},
+		{`CHOOSE(4, 'a', 'b', 'c')`, `NULL`},
+		{`CHOOSE(0, 'a', 'b', 'c')`, `NULL`},
+		{`CHOOSE(NULL, 'a', 'b', 'c')`, `NULL`},
+		{`CHOOSE(1, 'a', ERROR('not evaluated'))`, `a`},
+	}
+
+	vars := make([]Variable, 0)
+
+	for _, tc := range testCases {
+		result, err := Evaluate(tc.input, vars)
+		if err != nil {
+			t.Error(err)
+		}
+
+		if result.String() != tc.expect {
+			t.Errorf("Input: %s\nExpected: `%s`, got `%s`", tc.input, tc.expect, result.String())
+		}
+	}
+}
+
+func TestIN(t *testing.T) {
+	testCases := []struct {
+		input  string
+		expect string
+	}{
+		{`IN(2, 1, 2, 3)`, `1`},
+		{`IN(4, 1, 2, 3)`, `0`},
+		{`IN(NULL, 1, 2, 3)`, `NULL`},
+		{`IN(1, NULL, 1)`, `1`},
+		{`IN('a', 'b', 'a')`, `1`},
+		{`IN('A', 'b', 'a')`, `0`},
+		{`IN('A', 'b', 'a', 1)`, `0`},
+		{`IN('A', 'b', 'a', 0)`, `1`},
+		{`IN('A', 'b', 'a', 1 - 1)`, `1`},
+		{`IN(1, 1, ERROR('not evaluated'))`, `1`},
+	}
+
+	vars := make([]Variable, 0)
+
+	for _, tc := range testCases {
+		result, err := Evaluate(tc.input, vars)
+		if err != nil {
+			t.Error(err)
+		}
+
+		if result.String() != tc.expect {
+			t.Errorf("Input: %s\nExpected: `%s`, got `%s`", tc.input, tc.expect, result.String())
+		}
+	}
+}
+
+func TestErrorFunctions(t *testing.T) {
+	_, err := Evaluate(`IIF(TRUE, ERROR('bad row'), 1)`, nil)
+	if rErr, ok := err.(*RowError); !ok || rErr.Message != "bad row" {
+		t.Errorf("Expected a RowError with the message 'bad row', got %T: %v", err, err)
+	}
+
+	_, err = Evaluate(`IIF(FALSE, 1, ABORT('stop'))`, nil)
+	if aErr, ok := err.(*AbortError); !ok || aErr.Message != "stop" {
+		t.Errorf("Expected an AbortError with the message 'stop', got %T: %v", err, err)
+	}
+}
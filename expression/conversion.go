@@ -0,0 +1,184 @@
+// conversion functions
+// https://docs.informatica.com/data-integration/powercenter/10-4-0/transformation-language-reference/functions.html
+
+package expression
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// numberPattern is the text IS_NUMBER accepts and the conversion functions can convert once leading and trailing
+// spaces are removed: an optional sign, digits with an optional decimal point and an optional exponent
+var numberPattern = regexp.MustCompile(`^[+-]?([0-9]+\.?[0-9]*|\.[0-9]+)([eE][+-]?[0-9]+)?$`)
+
+// isNumberText checks if a string holds a number, e.g. '  +3.45e+3 ' but not '3.45E-', '+123abc' or an empty string
+func isNumberText(s string) bool {
+	return numberPattern.MatchString(strings.TrimSpace(s))
+}
+
+// exactNumber returns the exact value of a number or a string holding one, so that conversions round the decimal
+// digits which were written rather than the nearest double, e.g. TO_INTEGER('2.5') is 3
+// A string which isn't a number is a row error naming the datatype it was being converted to
+func exactNumber(fn string, t Type, v Value) (r *big.Rat, err error) {
+	switch v.typ {
+	case TypeString:
+		if !isNumberText(v.s) {
+			err = &RowError{Function: fn, Message: fmt.Sprintf("invalid string for converting to %s: '%s'", t, v.s)}
+			return
+		}
+		r, _ = new(big.Rat).SetString(strings.TrimSpace(v.s))
+	case TypeInteger, TypeBigint:
+		r = new(big.Rat).SetInt64(v.i)
+	case TypeDecimal:
+		r = v.rat()
+	case TypeDouble:
+		if math.IsInf(v.f, 0) || math.IsNaN(v.f) {
+			err = &RowError{Function: fn, Message: fmt.Sprintf("%s cannot be converted to %s", formatDouble(v.f), t)}
+			return
+		}
+		r = v.rat()
+	default:
+		err = fmt.Errorf("%s expected a number or string but got %s", fn, v.typ)
+	}
+
+	return
+}
+
+// truncateFlag reads the flag of TO_INTEGER and TO_BIGINT: the decimal portion is truncated when it is TRUE or any
+// number other than 0, and rounded when it is FALSE, 0 or omitted
+func truncateFlag(fn string, args []Value) (truncate bool, err error) {
+	if len(args) < 2 {
+		return
+	}
+
+	flag, err := toNumeric(fn, args[1])
+	if err != nil {
+		return
+	}
+	truncate = flag.Float() != 0
+
+	return
+}
+
+func toInteger(args ...Value) (result Value, err error) {
+	return toWholeNumber("TO_INTEGER", TypeInteger, math.MinInt32, math.MaxInt32, args)
+}
+
+func toBigint(args ...Value) (result Value, err error) {
+	return toWholeNumber("TO_BIGINT", TypeBigint, math.MinInt64, math.MaxInt64, args)
+}
+
+// toWholeNumber converts a string or number to an integer of type t, rounding half away from zero or truncating
+// A result outside min to max is a row error
+func toWholeNumber(fn string, t Type, min, max int64, args []Value) (result Value, err error) {
+	if anyNull(args) {
+		result = NewNull(t)
+		return
+	}
+
+	truncate, err := truncateFlag(fn, args)
+	if err != nil {
+		return
+	}
+	r, err := exactNumber(fn, t, args[0])
+	if err != nil {
+		return
+	}
+
+	i := roundRat(r, 0, !truncate)
+	if !i.IsInt64() || i.Int64() < min || i.Int64() > max {
+		err = &RowError{Function: fn, Message: fmt.Sprintf("%s is out of the range of %s", i, t)}
+		return
+	}
+	if t == TypeInteger {
+		result = NewInteger(int32(i.Int64()))
+	} else {
+		result = NewBigint(i.Int64())
+	}
+
+	return
+}
+
+// toDecimal converts a string or number to a decimal with scale digits after the decimal point, rounding half away
+// from zero; without a scale the value keeps the digits it has
+func toDecimal(args ...Value) (result Value, err error) {
+	if anyNull(args) {
+		result = NewNull(TypeDecimal)
+		return
+	}
+
+	r, err := exactNumber("TO_DECIMAL", TypeDecimal, args[0])
+	if err != nil {
+		return
+	}
+
+	scale := ratScale(r)
+	if len(args) > 1 {
+		if scale, err = toPosition("TO_DECIMAL", args[1]); err != nil {
+			return
+		}
+		if scale < 0 || scale > maxDecimalPrecision {
+			err = &RowError{
+				Function: "TO_DECIMAL",
+				Message:  fmt.Sprintf("the scale %d must be between 0 and %d", scale, maxDecimalPrecision),
+			}
+			return
+		}
+	}
+
+	if result, err = exactDecimal(r, scale); err != nil {
+		err = &RowError{Function: "TO_DECIMAL", Message: err.Error()}
+	}
+
+	return
+}
+
+// toFloat converts a string or number to a double
+func toFloat(args ...Value) (result Value, err error) {
+	if args[0].IsNull() {
+		result = NewNull(TypeDouble)
+		return
+	}
+
+	r, err := exactNumber("TO_FLOAT", TypeDouble, args[0])
+	if err != nil {
+		return
+	}
+	f, _ := r.Float64()
+	result, err = doubleResult("TO_FLOAT", f)
+
+	return
+}
+
+// isNumber checks if a string holds a number that the conversion functions accept; numbers are always numbers
+func isNumber(args ...Value) (result Value, err error) {
+	v := args[0]
+	switch {
+	case v.IsNull():
+		result = NewNull(TypeInteger)
+	case v.typ.IsNumeric():
+		result = boolValue(true)
+	default:
+		result = boolValue(isNumberText(toText(v)))
+	}
+
+	return
+}
+
+// formatNumber renders a number the way TO_CHAR does: integers and high precision decimals in full and doubles with up
+// to 15 significant digits, without trailing zeros, e.g. 1010.99 or 1.08427649682088e+19
+func formatNumber(v Value) string {
+	switch v.typ {
+	case TypeInteger, TypeBigint:
+		return strconv.FormatInt(v.i, 10)
+	case TypeDecimal:
+		return v.String()
+	}
+
+	return formatDouble(v.f)
+}
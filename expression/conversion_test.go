@@ -0,0 +1,97 @@
+package expression
+
+import (
+	"testing"
+)
+
+func TestTOINTEGER(t *testing.T) {
+	runStringTests(t, []stringTestCase{
+		{`TO_INTEGER('15.6789')`, `16`},
+		{`TO_INTEGER('60.2', TRUE)`, `60`},
+		{`TO_INTEGER('-15.6789')`, `-16`},
+		{`TO_INTEGER('-15.6789', TRUE)`, `-15`},
+		{`TO_INTEGER('118.348', 1)`, `118`},
+		{`TO_INTEGER('2.5', FALSE)`, `3`},
+		{`TO_INTEGER('   123.87 ')`, `124`},
+		{`TO_INTEGER('+1.5e2')`, `150`},
+		{`TO_INTEGER(15.5)`, `16`},
+		{`TO_INTEGER(NULL)`, `NULL`},
+		{`TO_INTEGER('1', NULL)`, `NULL`},
+		{`TO_BIGINT('9223372036854775807')`, `9223372036854775807`},
+		{`TO_BIGINT('3000000000.9', TRUE)`, `3000000000`},
+		{`TO_BIGINT(' -3.45e9 ')`, `-3450000000`},
+		{
+			`TO_INTEGER('A12.3Grove')`,
+			`<<Expression Error>> [TO_INTEGER]: invalid string for converting to INTEGER: 'A12.3Grove'`,
+		},
+		{`TO_INTEGER('')`, `<<Expression Error>> [TO_INTEGER]: invalid string for converting to INTEGER: ''`},
+		{`TO_INTEGER('2147483648')`, `<<Expression Error>> [TO_INTEGER]: 2147483648 is out of the range of INTEGER`},
+		{`TO_INTEGER('1,000')`, `<<Expression Error>> [TO_INTEGER]: invalid string for converting to INTEGER: '1,000'`},
+		{
+			`TO_BIGINT('9223372036854775808')`,
+			`<<Expression Error>> [TO_BIGINT]: 9223372036854775808 is out of the range of BIGINT`,
+		},
+	})
+}
+
+func TestTODECIMAL(t *testing.T) {
+	runStringTests(t, []stringTestCase{
+		{`TO_DECIMAL('15.6789', 3)`, `15.679`},
+		{`TO_DECIMAL('60.2', 3)`, `60.2`},
+		{`TO_DECIMAL('118.348', 3)`, `118.348`},
+		{`TO_DECIMAL('15.6789')`, `15.6789`},
+		{`TO_DECIMAL(' 711 ')`, `711`},
+		{`TO_DECIMAL('1.5e-3')`, `0.0015`},
+		{`TO_DECIMAL('-2.5', 0)`, `-3`},
+		{`TO_DECIMAL(12, 2)`, `12`},
+		{`TO_DECIMAL(NULL, 2)`, `NULL`},
+		{`TO_FLOAT('15.6789')`, `15.6789`},
+		{`TO_FLOAT(' -3.45e+3 ')`, `-3450`},
+		{`TO_FLOAT(5)`, `5`},
+		{`TO_FLOAT(NULL)`, `NULL`},
+		{
+			`TO_DECIMAL('A12.3Grove')`,
+			`<<Expression Error>> [TO_DECIMAL]: invalid string for converting to DECIMAL: 'A12.3Grove'`,
+		},
+		{`TO_DECIMAL('1.5', 39)`, `<<Expression Error>> [TO_DECIMAL]: the scale 39 must be between 0 and 38`},
+		{
+			`TO_DECIMAL('1e40')`,
+			`<<Expression Error>> [TO_DECIMAL]: 10000000000000000000000000000000000000000 has more than 38 digits`,
+		},
+		{`TO_FLOAT('711A1')`, `<<Expression Error>> [TO_FLOAT]: invalid string for converting to DOUBLE: '711A1'`},
+		{`TO_FLOAT('1e400')`, `<<Expression Error>> [TO_FLOAT]: the result is out of range`},
+	})
+}
+
+func TestISNUMBER(t *testing.T) {
+	runStringTests(t, []stringTestCase{
+		{`IS_NUMBER('123.00')`, `1`},
+		{`IS_NUMBER('-3.45e+3')`, `1`},
+		{`IS_NUMBER('3.45E-')`, `0`},
+		{`IS_NUMBER('   ')`, `0`},
+		{`IS_NUMBER('')`, `0`},
+		{`IS_NUMBER('+123abc')`, `0`},
+		{`IS_NUMBER('  123')`, `1`},
+		{`IS_NUMBER('123  ')`, `1`},
+		{`IS_NUMBER('.5')`, `1`},
+		{`IS_NUMBER('ABC')`, `0`},
+		{`IS_NUMBER('-ABC')`, `0`},
+		{`IS_NUMBER('1,000')`, `0`},
+		{`IS_NUMBER(12)`, `1`},
+		{`IS_NUMBER(NULL)`, `NULL`},
+	})
+}
+
+func TestTOCHARNumbers(t *testing.T) {
+	runStringTests(t, []stringTestCase{
+		{`TO_CHAR(1010.99)`, `1010.99`},
+		{`TO_CHAR(-15.62567)`, `-15.62567`},
+		{`TO_CHAR(10842764968208837340)`, `1.08427649682088e+19`},
+		{`TO_CHAR(0)`, `0`},
+		{`TO_CHAR(33.150)`, `33.15`},
+		{`TO_CHAR(0.1 + 0.2)`, `0.3`},
+		{`TO_CHAR(9223372036854775807)`, `9223372036854775807`},
+		{`TO_CHAR(TO_DECIMAL('60.2', 3))`, `60.2`},
+		{`TO_CHAR(NULL)`, `NULL`},
+	})
+}
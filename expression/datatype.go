@@ -0,0 +1,156 @@
+// datatypes of ports, with the precision and scale that values are converted to when they are written to a port
+// https://docs.informatica.com/data-integration/powercenter/10-4-0/designer-guide/datatype-reference.html
+
+package expression
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// datatypePattern matches a datatype with an optional precision and scale, e.g. string(10) or decimal(12, 2)
+var datatypePattern = regexp.MustCompile(`^\s*([A-Za-z/]+)\s*(?:\(\s*(\d+)\s*(?:,\s*(\d+)\s*)?\))?\s*$`)
+
+// ParseDatatype converts a port datatype, e.g. string(10), decimal(12,2), integer or date/time, to its Type and the
+// precision and scale written in brackets, which are 0 when they are omitted
+func ParseDatatype(datatype string) (t Type, precision int, scale int, err error) {
+	m := datatypePattern.FindStringSubmatch(datatype)
+	if m == nil {
+		err = fmt.Errorf("unknown datatype '%s'", datatype)
+		return
+	}
+
+	if t, err = ParseType(m[1]); err != nil {
+		return
+	}
+	if m[2] != "" {
+		precision, _ = strconv.Atoi(m[2])
+	}
+	if m[3] != "" {
+		scale, _ = strconv.Atoi(m[3])
+	}
+
+	switch {
+	case m[3] != "" && t != TypeDecimal:
+		err = fmt.Errorf("only a decimal has a scale, got '%s'", datatype)
+	case m[2] != "" && t != TypeString && t != TypeDecimal && t != TypeBinary:
+		err = fmt.Errorf("only a string, decimal or binary has a precision, got '%s'", datatype)
+	case t == TypeDecimal && (precision > maxDecimalPrecision || scale > precision && m[2] != ""):
+		err = fmt.Errorf("a decimal has a precision of up to %d and a scale up to its precision, got '%s'",
+			maxDecimalPrecision, datatype)
+	}
+
+	return
+}
+
+// Datatype returns the datatype of the port as it is written in the Designer, e.g. string(10) or decimal(12,2)
+func (p Port) Datatype() string {
+	name := strings.ToLower(p.Type.String())
+	switch {
+	case p.Type == TypeDecimal && p.Precision > 0:
+		return fmt.Sprintf("%s(%d,%d)", name, p.Precision, p.Scale)
+	case (p.Type == TypeString || p.Type == TypeBinary) && p.Precision > 0:
+		return fmt.Sprintf("%s(%d)", name, p.Precision)
+	}
+
+	return name
+}
+
+// convert converts a value written to the port to its datatype as the Integration Service does: strings and binaries
+// are truncated to the precision, numbers are rounded to the nearest integer or the scale of a decimal, and numbers
+// which are too large for the port are row errors, as are strings which aren't numbers or dates
+// A precision of 0 doesn't limit the length of strings and binaries, and allows decimals of up to 38 digits
+func (p Port) convert(v Value) (result Value, err error) {
+	if v.IsNull() {
+		result = NewNull(p.Type)
+		return
+	}
+
+	switch p.Type {
+	case TypeNull:
+		result = v
+	case TypeString:
+		s := toText(v)
+		if p.Precision > 0 && utf8.RuneCountInString(s) > p.Precision {
+			s = string([]rune(s)[:p.Precision])
+		}
+		result = NewString(s)
+	case TypeInteger:
+		result, err = toWholeNumber(p.Name, TypeInteger, math.MinInt32, math.MaxInt32, []Value{v})
+	case TypeBigint:
+		result, err = toWholeNumber(p.Name, TypeBigint, math.MinInt64, math.MaxInt64, []Value{v})
+	case TypeDecimal:
+		result, err = p.decimal(v)
+	case TypeDouble:
+		var n Value
+		if n, err = toNumeric(p.Name, v); err != nil {
+			err = &RowError{Function: p.Name, Message: fmt.Sprintf("invalid string for converting to %s: '%s'", p.Type, v)}
+			return
+		}
+		result = NewDouble(n.Float())
+	case TypeDate:
+		result, err = p.date(v)
+	case TypeBinary:
+		if v.typ != TypeBinary {
+			err = &RowError{Function: p.Name, Message: fmt.Sprintf("a %s can't be converted to %s", v.typ, p.Type)}
+			return
+		}
+		b := v.b
+		if p.Precision > 0 && len(b) > p.Precision {
+			b = b[:p.Precision]
+		}
+		result = NewBinary(b)
+	}
+
+	return
+}
+
+// decimal rounds a number to the scale of a decimal port and checks its integer digits fit in the precision
+func (p Port) decimal(v Value) (result Value, err error) {
+	r, err := exactNumber(p.Name, p.Type, v)
+	if err != nil {
+		return
+	}
+
+	precision := p.Precision
+	if precision == 0 {
+		precision = maxDecimalPrecision
+	}
+	if result, err = exactDecimal(r, p.Scale); err == nil && result.scale == p.Scale {
+		digits := len(new(big.Int).Abs(result.d).String())
+		if digits <= precision || result.d.Sign() == 0 {
+			result.precision = precision
+			return
+		}
+	}
+	err = &RowError{
+		Function: p.Name,
+		Message:  fmt.Sprintf("%s overflows %s", r.FloatString(p.Scale), p.Datatype()),
+	}
+
+	return
+}
+
+// date converts a string in one of the default date formats to a date
+func (p Port) date(v Value) (result Value, err error) {
+	switch v.typ {
+	case TypeDate:
+		result = v
+	case TypeString:
+		d, dErr := parseDefaultDate(v.s)
+		if dErr != nil {
+			err = &RowError{Function: p.Name, Message: fmt.Sprintf("invalid string for converting to %s: '%s'", p.Type, v.s)}
+			return
+		}
+		result = NewDate(d)
+	default:
+		err = &RowError{Function: p.Name, Message: fmt.Sprintf("a %s can't be converted to %s", v.typ, p.Type)}
+	}
+
+	return
+}
@@ -0,0 +1,179 @@
+package expression
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseDatatype(t *testing.T) {
+	testCases := []struct {
+		input  string
+		expect Port
+	}{
+		{"string(10)", Port{Type: TypeString, Precision: 10}},
+		{"String", Port{Type: TypeString}},
+		{"decimal(12, 2)", Port{Type: TypeDecimal, Precision: 12, Scale: 2}},
+		{"decimal(5)", Port{Type: TypeDecimal, Precision: 5}},
+		{"integer", Port{Type: TypeInteger}},
+		{"bigint", Port{Type: TypeBigint}},
+		{"date/time", Port{Type: TypeDate}},
+		{"double", Port{Type: TypeDouble}},
+		{"binary(4)", Port{Type: TypeBinary, Precision: 4}},
+	}
+
+	for _, tc := range testCases {
+		typ, precision, scale, err := ParseDatatype(tc.input)
+		if err != nil {
+			t.Errorf("Input: %s\nUnexpected error: %v", tc.input, err)
+			continue
+		}
+		port := Port{Type: typ, Precision: precision, Scale: scale}
+		if port != tc.expect {
+			t.Errorf("Input: %s\nExpected: `%s`, got `%s`", tc.input, tc.expect.Datatype(), port.Datatype())
+		}
+	}
+
+	for _, input := range []string{"string(", "integer(10)", "string(10,2)", "decimal(40,2)", "decimal(5,6)", "text10"} {
+		if _, _, _, err := ParseDatatype(input); err == nil {
+			t.Errorf("Input: %s\nExpected an error", input)
+		}
+	}
+}
+
+func TestPortConversion(t *testing.T) {
+	testCases := []struct {
+		datatype string
+		input    Value
+		expect   string
+	}{
+		{"string(5)", NewString("abcdefg"), "abcde"},
+		{"string(5)", NewString("ab"), "ab"},
+		{"string(3)", NewInteger(12345), "123"},
+		{"string", NewString("abcdefg"), "abcdefg"},
+		{"decimal(5,2)", NewDouble(123.456), "123.46"},
+		{"decimal(5,2)", NewString("-1.005"), "-1.01"},
+		{"decimal(5,0)", NewInteger(99999), "99999"},
+		{"decimal(10,3)", NewInteger(7), "7.000"},
+		{"integer", NewDouble(2.5), "3"},
+		{"integer", NewString("-42"), "-42"},
+		{"integer", NewBigint(2147483647), "2147483647"},
+		{"bigint", NewDouble(-9e15), "-9000000000000000"},
+		{"double", NewString("1.25"), "1.25"},
+		{"date/time", NewString("03/15/2020"), "03/15/2020 00:00:00.000000"},
+		{"binary(2)", NewBinary([]byte("abc")), "6162"},
+		{"integer", Value{}, "NULL"},
+	}
+
+	for _, tc := range testCases {
+		typ, precision, scale, err := ParseDatatype(tc.datatype)
+		if err != nil {
+			t.Fatal(err)
+		}
+		port := Port{Name: "o_PORT", Type: typ, Precision: precision, Scale: scale}
+		result, err := port.convert(tc.input)
+		if err != nil {
+			t.Errorf("Input: %s to %s\nUnexpected error: %v", tc.input, tc.datatype, err)
+			continue
+		}
+		if result.String() != tc.expect || !result.IsNull() && result.Type() != typ {
+			t.Errorf("Input: %s to %s\nExpected: `%s`, got `%s %s`", tc.input, tc.datatype, tc.expect, result,
+				result.Type())
+		}
+	}
+}
+
+func TestPortConversionErrors(t *testing.T) {
+	testCases := []struct {
+		datatype string
+		input    Value
+	}{
+		{"integer", NewBigint(2147483648)},
+		{"integer", NewDouble(-3e9)},
+		{"bigint", NewDouble(1e19)},
+		{"decimal(5,2)", NewDouble(1234.5)},
+		{"decimal(3,0)", NewString("999.5")},
+		{"integer", NewString("12abc")},
+		{"double", NewString("abc")},
+		{"date/time", NewString("not a date")},
+		{"date/time", NewInteger(1)},
+		{"binary", NewString("abc")},
+	}
+
+	for _, tc := range testCases {
+		typ, precision, scale, err := ParseDatatype(tc.datatype)
+		if err != nil {
+			t.Fatal(err)
+		}
+		port := Port{Name: "o_PORT", Type: typ, Precision: precision, Scale: scale}
+		_, err = port.convert(tc.input)
+		var rowErr *RowError
+		if !errors.As(err, &rowErr) || rowErr.Function != "o_PORT" {
+			t.Errorf("Input: %s to %s\nExpected a row error for o_PORT, got %v", tc.input, tc.datatype, err)
+		}
+	}
+}
+
+func TestTransformationDatatypes(t *testing.T) {
+	transformation, err := NewTransformation([]Port{
+		{Name: "in_NAME", Kind: PortInput, Type: TypeString, Precision: 4},
+		{Name: "in_PRICE", Kind: PortInputOutput, Type: TypeDecimal, Precision: 6, Scale: 2},
+		{Name: "in_QTY", Kind: PortInput, Type: TypeInteger},
+		{Name: "v_TOTAL", Kind: PortVariable, Type: TypeDecimal, Precision: 8, Scale: 1,
+			Expression: "v_TOTAL + in_PRICE * in_QTY"},
+		{Name: "o_NAME", Kind: PortOutput, Type: TypeString, Precision: 6, Expression: "CONCAT(in_NAME, '-xyz')"},
+		{Name: "o_TOTAL", Kind: PortOutput, Type: TypeDecimal, Precision: 8, Scale: 1, Expression: "v_TOTAL"},
+		{Name: "o_QTY", Kind: PortOutput, Type: TypeInteger, Expression: "in_QTY * 1000000"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	output, err := transformation.Next(Row{
+		"in_NAME": NewString("abcdef"), "in_PRICE": NewDouble(1.255), "in_QTY": NewString("3"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result := outputString(output, "o_NAME", "in_PRICE", "o_TOTAL", "o_QTY"); result != "abcd-x,1.26,3.8,3000000" {
+		t.Errorf("Expected: `abcd-x,1.26,3.8,3000000`, got `%s`", result)
+	}
+
+	// 3000 * 1000000 overflows the integer output port
+	_, err = transformation.Next(Row{"in_NAME": NewString("a"), "in_PRICE": NewDouble(1), "in_QTY": NewInteger(3000)})
+	var rowErr *RowError
+	if !errors.As(err, &rowErr) || rowErr.Function != "o_QTY" {
+		t.Errorf("Expected a row error for o_QTY, got %v", err)
+	}
+
+	// a string which isn't a number can't be read into an integer input port
+	_, err = transformation.Next(Row{"in_QTY": NewString("three")})
+	if !errors.As(err, &rowErr) || rowErr.Function != "in_QTY" {
+		t.Errorf("Expected a row error for in_QTY, got %v", err)
+	}
+}
+
+func TestVariableDatatypes(t *testing.T) {
+	vars := []Variable{
+		{N: "in_CODE", T: "string(3)", V: "ABCDE"},
+		{N: "in_RATE", T: "decimal(4,2)", V: "0.125"},
+	}
+	testCases := []stringTestCase{
+		{`in_CODE`, `ABC`},
+		{`in_RATE`, `0.13`},
+	}
+
+	for _, tc := range testCases {
+		result, err := Evaluate(tc.input, vars)
+		if err != nil {
+			t.Errorf("Input: %s\nUnexpected error: %v", tc.input, err)
+			continue
+		}
+		if result.String() != tc.expect {
+			t.Errorf("Input: %s\nExpected: `%s`, got `%s`", tc.input, tc.expect, result.String())
+		}
+	}
+
+	if _, err := Evaluate(`in_RATE`, []Variable{{N: "in_RATE", T: "decimal(4,2)", V: "123"}}); err == nil {
+		t.Error("Expected an error for a value which overflows its decimal datatype")
+	}
+}
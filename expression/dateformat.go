@@ -0,0 +1,436 @@
+// the date format string language used by TO_DATE, TO_CHAR, IS_DATE and the date part arguments of the date functions
+// https://docs.informatica.com/data-integration/powercenter/10-4-0/transformation-language-reference/dates/date-format-strings.html
+
+package expression
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// dateFormatCodes are the format codes, longest first so that e.g. MONTH is matched before MON and MM
+var dateFormatCodes = []string{
+	"MONTH", "SSSSS",
+	"A.M.", "P.M.", "HH12", "HH24", "YYYY",
+	"DAY", "DDD", "MON", "YYY",
+	"AM", "DD", "DY", "HH", "MI", "MM", "MS", "NS", "PM", "RR", "SS", "US", "WW", "YY",
+	"D", "J", "Q", "W", "Y",
+}
+
+// dateFormatPart is either a format code or literal text which is copied as is
+type dateFormatPart struct {
+	code    string
+	literal string
+}
+
+// parseDateFormat splits a format string into its codes and literal text
+// Codes are case insensitive, text in double quotes is literal, and so are punctuation and white space
+func parseDateFormat(format string) (parts []dateFormatPart, err error) {
+	upper := strings.ToUpper(format)
+	for i := 0; i < len(format); {
+		if format[i] == '"' {
+			end := strings.IndexByte(format[i+1:], '"')
+			if end < 0 {
+				err = fmt.Errorf("the date format '%s' has an unclosed quote", format)
+				return
+			}
+			parts = append(parts, dateFormatPart{literal: format[i+1 : i+1+end]})
+			i += end + 2
+			continue
+		}
+
+		code := ""
+		for _, c := range dateFormatCodes {
+			if strings.HasPrefix(upper[i:], c) {
+				code = c
+				break
+			}
+		}
+		if code != "" {
+			parts = append(parts, dateFormatPart{code: code})
+			i += len(code)
+			continue
+		}
+
+		r := rune(format[i])
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			err = fmt.Errorf("the date format '%s' has an invalid code at '%s'", format, format[i:])
+			return
+		}
+		parts = append(parts, dateFormatPart{literal: format[i : i+1]})
+		i++
+	}
+
+	return
+}
+
+// julianEpoch is the Julian day number of 01/01/1970
+const julianEpoch = 2440588
+
+// formatDateWith renders a date using an Informatica date format string
+func formatDateWith(t time.Time, format string) (s string, err error) {
+	parts, err := parseDateFormat(format)
+	if err != nil {
+		return
+	}
+
+	var b strings.Builder
+	for _, part := range parts {
+		if part.code == "" {
+			b.WriteString(part.literal)
+			continue
+		}
+
+		hour12 := t.Hour() % 12
+		if hour12 == 0 {
+			hour12 = 12
+		}
+		switch part.code {
+		case "YYYY":
+			fmt.Fprintf(&b, "%04d", t.Year())
+		case "YYY":
+			fmt.Fprintf(&b, "%03d", t.Year()%1000)
+		case "YY", "RR":
+			fmt.Fprintf(&b, "%02d", t.Year()%100)
+		case "Y":
+			fmt.Fprintf(&b, "%d", t.Year()%10)
+		case "MONTH":
+			b.WriteString(t.Month().String())
+		case "MON":
+			b.WriteString(t.Month().String()[:3])
+		case "MM":
+			fmt.Fprintf(&b, "%02d", int(t.Month()))
+		case "DAY":
+			b.WriteString(t.Weekday().String())
+		case "DY":
+			b.WriteString(t.Weekday().String()[:3])
+		case "DDD":
+			fmt.Fprintf(&b, "%03d", t.YearDay())
+		case "DD":
+			fmt.Fprintf(&b, "%02d", t.Day())
+		case "D":
+			fmt.Fprintf(&b, "%d", int(t.Weekday())+1)
+		case "HH", "HH12":
+			fmt.Fprintf(&b, "%02d", hour12)
+		case "HH24":
+			fmt.Fprintf(&b, "%02d", t.Hour())
+		case "MI":
+			fmt.Fprintf(&b, "%02d", t.Minute())
+		case "SS":
+			fmt.Fprintf(&b, "%02d", t.Second())
+		case "SSSSS":
+			fmt.Fprintf(&b, "%05d", t.Hour()*3600+t.Minute()*60+t.Second())
+		case "MS":
+			fmt.Fprintf(&b, "%03d", t.Nanosecond()/1e6)
+		case "US":
+			fmt.Fprintf(&b, "%06d", t.Nanosecond()/1e3)
+		case "NS":
+			fmt.Fprintf(&b, "%09d", t.Nanosecond())
+		case "AM", "PM":
+			if t.Hour() < 12 {
+				b.WriteString("AM")
+			} else {
+				b.WriteString("PM")
+			}
+		case "A.M.", "P.M.":
+			if t.Hour() < 12 {
+				b.WriteString("A.M.")
+			} else {
+				b.WriteString("P.M.")
+			}
+		case "J":
+			fmt.Fprintf(&b, "%d", julianDay(t))
+		case "Q":
+			fmt.Fprintf(&b, "%d", (int(t.Month())+2)/3)
+		case "W":
+			fmt.Fprintf(&b, "%d", (t.Day()-1)/7+1)
+		case "WW":
+			fmt.Fprintf(&b, "%d", (t.YearDay()-1)/7+1)
+		}
+	}
+	s = b.String()
+
+	return
+}
+
+// julianDay returns the number of days since January 1, 4713 BC
+func julianDay(t time.Time) int64 {
+	days := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC).Unix() / 86400
+
+	return days + julianEpoch
+}
+
+// dateFields are the parts of a date read from a string before they are checked and combined
+type dateFields struct {
+	year, month, day, yearDay   int
+	hour, minute, second, nanos int
+	pm, meridian                bool
+	julian                      int64
+	hasJulian, hasYearDay       bool
+}
+
+// parseDateWith reads a date from a string in an Informatica date format
+// Numbers may have fewer digits than their code when followed by a separator, e.g. 1/5/2020 for MM/DD/YYYY, and
+// fractional seconds are read as a fraction so that .5 with US is half a second
+// now decides the century of the two digit years RR and YY
+func parseDateWith(value string, format string, now time.Time) (t time.Time, err error) {
+	parts, err := parseDateFormat(format)
+	if err != nil {
+		return
+	}
+
+	f := dateFields{year: 1, month: 1, day: 1} // the parts which aren't in the format are from 01/01/0001
+	invalid := func() error {
+		return fmt.Errorf("'%s' does not match the date format '%s'", value, format)
+	}
+
+	pos := 0
+	for _, part := range parts {
+		if part.code == "" {
+			if !strings.HasPrefix(value[pos:], part.literal) {
+				err = invalid()
+				return
+			}
+			pos += len(part.literal)
+			continue
+		}
+
+		n, digits := 0, 0
+		readNumber := func(width int) bool {
+			n, digits = 0, 0
+			for digits < width && pos < len(value) && value[pos] >= '0' && value[pos] <= '9' {
+				n = n*10 + int(value[pos]-'0')
+				pos++
+				digits++
+			}
+			return digits > 0
+		}
+		readName := func(names []string) (int, bool) {
+			for i, name := range names {
+				if len(value)-pos >= len(name) && strings.EqualFold(value[pos:pos+len(name)], name) {
+					pos += len(name)
+					return i, true
+				}
+			}
+			return 0, false
+		}
+
+		ok := true
+		switch part.code {
+		case "YYYY":
+			ok = readNumber(4)
+			f.year = n
+		case "YYY", "YY", "Y":
+			ok = readNumber(len(part.code))
+			century := 1
+			for i := 0; i < len(part.code); i++ {
+				century *= 10
+			}
+			f.year = now.Year() - now.Year()%century + n
+		case "RR":
+			ok = readNumber(2)
+			f.year = rrYear(n, now.Year())
+		case "MM":
+			ok = readNumber(2)
+			f.month = n
+		case "MONTH", "MON":
+			var i int
+			if part.code == "MONTH" {
+				i, ok = readName(monthNames(false))
+			} else {
+				i, ok = readName(monthNames(true))
+			}
+			f.month = i + 1
+		case "DDD":
+			ok = readNumber(3)
+			f.yearDay, f.hasYearDay = n, true
+		case "DD":
+			ok = readNumber(2)
+			f.day = n
+		case "D":
+			ok = readNumber(1) && n >= 1 && n <= 7 // the day of the week is read but doesn't change the date
+		case "DAY":
+			_, ok = readName(weekdayNames(false))
+		case "DY":
+			_, ok = readName(weekdayNames(true))
+		case "HH", "HH12":
+			ok = readNumber(2) && n >= 1 && n <= 12
+			f.hour, f.meridian = n%12, true
+		case "HH24":
+			ok = readNumber(2)
+			f.hour = n
+		case "MI":
+			ok = readNumber(2)
+			f.minute = n
+		case "SS":
+			ok = readNumber(2)
+			f.second = n
+		case "SSSSS":
+			ok = readNumber(5) && n < 86400
+			f.hour, f.minute, f.second = n/3600, n/60%60, n%60
+		case "MS", "US", "NS":
+			width := map[string]int{"MS": 3, "US": 6, "NS": 9}[part.code]
+			ok = readNumber(width)
+			for i := digits; i < 9; i++ {
+				n *= 10
+			}
+			f.nanos = n
+		case "AM", "PM", "A.M.", "P.M.":
+			var i int
+			if strings.Contains(part.code, ".") {
+				i, ok = readName([]string{"A.M.", "P.M."})
+			} else {
+				i, ok = readName([]string{"AM", "PM"})
+			}
+			f.pm = i == 1
+		case "J":
+			ok = readNumber(7)
+			f.julian, f.hasJulian = int64(n), true
+		default:
+			// Q, W and WW can be written but not read
+			err = fmt.Errorf("the date format code %s can't be used to read a date", part.code)
+			return
+		}
+		if !ok {
+			err = invalid()
+			return
+		}
+	}
+	if pos < len(value) {
+		err = invalid()
+		return
+	}
+
+	return f.date(value)
+}
+
+// date checks the fields are a valid date and combines them
+func (f dateFields) date(value string) (t time.Time, err error) {
+	if f.meridian && f.pm {
+		f.hour += 12
+	}
+
+	switch {
+	case f.hasJulian:
+		t = time.Unix((f.julian-julianEpoch)*86400, 0).UTC()
+		f.year, f.month, f.day = t.Year(), int(t.Month()), t.Day()
+	case f.hasYearDay:
+		if f.yearDay < 1 || f.yearDay > time.Date(f.year, time.December, 31, 0, 0, 0, 0, time.UTC).YearDay() {
+			err = fmt.Errorf("'%s' has an invalid day of the year %d", value, f.yearDay)
+			return
+		}
+		t = time.Date(f.year, time.January, f.yearDay, 0, 0, 0, 0, time.UTC)
+		f.month, f.day = int(t.Month()), t.Day()
+	}
+
+	switch {
+	case f.year < 1 || f.year > 9999:
+		err = fmt.Errorf("'%s' has an invalid year %d", value, f.year)
+	case f.month < 1 || f.month > 12:
+		err = fmt.Errorf("'%s' has an invalid month %d", value, f.month)
+	case f.day < 1 || f.day > daysIn(time.Month(f.month), f.year):
+		err = fmt.Errorf("'%s' has an invalid day %d", value, f.day)
+	case f.hour > 23:
+		err = fmt.Errorf("'%s' has an invalid hour %d", value, f.hour)
+	case f.minute > 59:
+		err = fmt.Errorf("'%s' has an invalid minute %d", value, f.minute)
+	case f.second > 59:
+		err = fmt.Errorf("'%s' has an invalid second %d", value, f.second)
+	}
+	if err != nil {
+		return
+	}
+	t = time.Date(f.year, time.Month(f.month), f.day, f.hour, f.minute, f.second, f.nanos, time.UTC)
+
+	return
+}
+
+// rrYear applies the RR century rules to a two digit year:
+// in the first half of a century 00-49 are in the current century and 50-99 in the previous one,
+// in the second half 00-49 are in the next century and 50-99 in the current one
+func rrYear(yy int, currentYear int) int {
+	century := currentYear - currentYear%100
+	switch {
+	case currentYear%100 < 50 && yy >= 50:
+		century -= 100
+	case currentYear%100 >= 50 && yy < 50:
+		century += 100
+	}
+
+	return century + yy
+}
+
+// daysIn returns the number of days in the month
+func daysIn(month time.Month, year int) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+func monthNames(abbreviated bool) (names []string) {
+	for m := time.January; m <= time.December; m++ {
+		if abbreviated {
+			names = append(names, m.String()[:3])
+		} else {
+			names = append(names, m.String())
+		}
+	}
+
+	return
+}
+
+func weekdayNames(abbreviated bool) (names []string) {
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		if abbreviated {
+			names = append(names, d.String()[:3])
+		} else {
+			names = append(names, d.String())
+		}
+	}
+
+	return
+}
+
+// datePart returns the unit of a date format code used by ADD_TO_DATE, DATE_DIFF, GET_DATE_PART, SET_DATE_PART,
+// ROUND and TRUNC: one of YYYY, MM, DD, HH, MI, SS, MS, US or NS
+func datePart(fn string, format string) (part string, err error) {
+	switch strings.ToUpper(strings.TrimSpace(format)) {
+	case "Y", "YY", "YYY", "YYYY", "RR":
+		part = "YYYY"
+	case "MM", "MON", "MONTH":
+		part = "MM"
+	case "D", "DD", "DDD", "DY", "DAY":
+		part = "DD"
+	case "HH", "HH12", "HH24":
+		part = "HH"
+	case "MI":
+		part = "MI"
+	case "SS":
+		part = "SS"
+	case "MS":
+		part = "MS"
+	case "US":
+		part = "US"
+	case "NS":
+		part = "NS"
+	default:
+		err = &RowError{Function: fn, Message: fmt.Sprintf("'%s' is not a valid date format", format)}
+	}
+
+	return
+}
+
+// dateFormats are tried in order when reading a date without a format string
+var dateFormats = []string{DefaultDateFormat, "MM/DD/YYYY HH24:MI:SS", "MM/DD/YYYY"}
+
+// parseDefaultDate reads a date in PowerCenter's default date format, with or without the fractional seconds or time
+func parseDefaultDate(value string) (t time.Time, err error) {
+	for _, format := range dateFormats {
+		if t, err = parseDateWith(value, format, time.Time{}); err == nil {
+			return
+		}
+	}
+	err = fmt.Errorf("'%s' is not a date in the format %s", value, DefaultDateFormat)
+
+	return
+}
@@ -0,0 +1,518 @@
+// date functions
+// https://docs.informatica.com/data-integration/powercenter/10-4-0/transformation-language-reference/dates.html
+
+package expression
+
+import (
+	"fmt"
+	"time"
+)
+
+// toTime returns the time of a date argument
+func toTime(fn string, v Value) (t time.Time, err error) {
+	if v.typ != TypeDate {
+		err = fmt.Errorf("%s expects a date, got %s", fn, v.typ)
+		return
+	}
+	t = v.t
+
+	return
+}
+
+// toDateFormat returns the text of a format argument
+func toDateFormat(fn string, v Value) (format string, err error) {
+	if v.typ != TypeString {
+		err = fmt.Errorf("%s expects the format to be a string, got %s", fn, v.typ)
+		return
+	}
+	format = v.s
+
+	return
+}
+
+// checkDate raises a row error for dates outside of the range PowerCenter supports, 01/01/0001 to 12/31/9999
+func checkDate(fn string, t time.Time) (result Value, err error) {
+	if t.Year() < 1 || t.Year() > 9999 {
+		err = &RowError{Function: fn, Message: fmt.Sprintf("the date is out of range, year %d", t.Year())}
+		return
+	}
+	result = NewDate(t)
+
+	return
+}
+
+// addMonths adds months to the date, moving to the last day of the month when the day doesn't exist in the new month,
+// e.g. adding a month to January 31 is the last day of February
+func addMonths(t time.Time, months int) time.Time {
+	first := time.Date(t.Year(), t.Month(), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+	first = first.AddDate(0, months, 0)
+	day := t.Day()
+	if last := daysIn(first.Month(), first.Year()); day > last {
+		day = last
+	}
+
+	return first.AddDate(0, 0, day-1)
+}
+
+// addToDate adds an amount of the date part given by format to the date
+func addToDate(args ...Value) (result Value, err error) {
+	if anyNull(args) {
+		result = NewNull(TypeDate)
+		return
+	}
+
+	t, err := toTime("ADD_TO_DATE", args[0])
+	if err != nil {
+		return
+	}
+	format, err := toDateFormat("ADD_TO_DATE", args[1])
+	if err != nil {
+		return
+	}
+	part, err := datePart("ADD_TO_DATE", format)
+	if err != nil {
+		return
+	}
+	amount, err := toPosition("ADD_TO_DATE", args[2])
+	if err != nil {
+		return
+	}
+
+	switch part {
+	case "YYYY":
+		t = addMonths(t, amount*12)
+	case "MM":
+		t = addMonths(t, amount)
+	case "DD":
+		t = t.AddDate(0, 0, amount)
+	default:
+		// whole days are added separately since a large amount of a short part overflows a Duration
+		perDay := int(24 * time.Hour / partDuration[part])
+		t = t.AddDate(0, 0, amount/perDay).Add(time.Duration(amount%perDay) * partDuration[part])
+	}
+	result, err = checkDate("ADD_TO_DATE", t)
+
+	return
+}
+
+// partDuration is the length of the date parts shorter than a day
+var partDuration = map[string]time.Duration{
+	"HH": time.Hour,
+	"MI": time.Minute,
+	"SS": time.Second,
+	"MS": time.Millisecond,
+	"US": time.Microsecond,
+	"NS": time.Nanosecond,
+}
+
+// dateCompare returns -1 if the first date is earlier, 0 if the dates are equal and 1 if the first date is later
+func dateCompare(args ...Value) (result Value, err error) {
+	if anyNull(args) {
+		result = NewNull(TypeInteger)
+		return
+	}
+
+	t1, err := toTime("DATE_COMPARE", args[0])
+	if err != nil {
+		return
+	}
+	t2, err := toTime("DATE_COMPARE", args[1])
+	if err != nil {
+		return
+	}
+
+	switch {
+	case t1.Before(t2):
+		result = NewInteger(-1)
+	case t1.After(t2):
+		result = NewInteger(1)
+	default:
+		result = NewInteger(0)
+	}
+
+	return
+}
+
+// dateDiff returns the length of time between two dates (date1 - date2) in the date part given by format
+// The result is fractional; months are counted as whole months plus the difference in days divided by 31, and years
+// as months divided by 12, so DATE_DIFF of 01/01/1997 and 03/29/1997 12:00 in MM is -2.91935483870968
+func dateDiff(args ...Value) (result Value, err error) {
+	if anyNull(args) {
+		result = NewNull(TypeDouble)
+		return
+	}
+
+	t1, err := toTime("DATE_DIFF", args[0])
+	if err != nil {
+		return
+	}
+	t2, err := toTime("DATE_DIFF", args[1])
+	if err != nil {
+		return
+	}
+	format, err := toDateFormat("DATE_DIFF", args[2])
+	if err != nil {
+		return
+	}
+	part, err := datePart("DATE_DIFF", format)
+	if err != nil {
+		return
+	}
+
+	switch part {
+	case "YYYY":
+		result = NewDouble(monthsBetween(t1, t2) / 12)
+	case "MM":
+		result = NewDouble(monthsBetween(t1, t2))
+	case "DD":
+		result = NewDouble(nanosecondsBetween(t1, t2) / float64(24*time.Hour))
+	default:
+		result = NewDouble(nanosecondsBetween(t1, t2) / float64(partDuration[part]))
+	}
+
+	return
+}
+
+// secondsBetween returns t1 - t2 in seconds without the 290 year limit of time.Duration
+func secondsBetween(t1 time.Time, t2 time.Time) float64 {
+	return float64(t1.Unix()-t2.Unix()) + float64(t1.Nanosecond()-t2.Nanosecond())/1e9
+}
+
+// nanosecondsBetween returns t1 - t2 in nanoseconds, which is exact when the dates are less than 290 years apart
+func nanosecondsBetween(t1 time.Time, t2 time.Time) float64 {
+	seconds := t1.Unix() - t2.Unix()
+	if seconds > -9e9 && seconds < 9e9 {
+		return float64(seconds*1e9 + int64(t1.Nanosecond()-t2.Nanosecond()))
+	}
+
+	return secondsBetween(t1, t2) * 1e9
+}
+
+// monthsBetween returns t1 - t2 in months, with the part of a month counted in 31 day months
+func monthsBetween(t1 time.Time, t2 time.Time) float64 {
+	months := (t1.Year()-t2.Year())*12 + int(t1.Month()) - int(t2.Month())
+	days := func(t time.Time) float64 {
+		midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+		return float64(t.Day()-1) + secondsBetween(t, midnight)/86400
+	}
+
+	return float64(months) + (days(t1)-days(t2))/31
+}
+
+// getDatePart returns the date part given by format as an integer, e.g. the month
+func getDatePart(args ...Value) (result Value, err error) {
+	if anyNull(args) {
+		result = NewNull(TypeInteger)
+		return
+	}
+
+	t, err := toTime("GET_DATE_PART", args[0])
+	if err != nil {
+		return
+	}
+	format, err := toDateFormat("GET_DATE_PART", args[1])
+	if err != nil {
+		return
+	}
+	part, err := datePart("GET_DATE_PART", format)
+	if err != nil {
+		return
+	}
+
+	var n int
+	switch part {
+	case "YYYY":
+		n = t.Year()
+	case "MM":
+		n = int(t.Month())
+	case "DD":
+		n = t.Day()
+	case "HH":
+		n = t.Hour()
+	case "MI":
+		n = t.Minute()
+	case "SS":
+		n = t.Second()
+	case "MS":
+		n = t.Nanosecond() / 1e6
+	case "US":
+		n = t.Nanosecond() / 1e3
+	case "NS":
+		n = t.Nanosecond()
+	}
+	result = NewInteger(int32(n))
+
+	return
+}
+
+// setDatePart sets the date part given by format to value; an invalid date, e.g. February 30, is a row error
+func setDatePart(args ...Value) (result Value, err error) {
+	if anyNull(args) {
+		result = NewNull(TypeDate)
+		return
+	}
+
+	t, err := toTime("SET_DATE_PART", args[0])
+	if err != nil {
+		return
+	}
+	format, err := toDateFormat("SET_DATE_PART", args[1])
+	if err != nil {
+		return
+	}
+	part, err := datePart("SET_DATE_PART", format)
+	if err != nil {
+		return
+	}
+	n, err := toPosition("SET_DATE_PART", args[2])
+	if err != nil {
+		return
+	}
+
+	f := dateFields{
+		year: t.Year(), month: int(t.Month()), day: t.Day(),
+		hour: t.Hour(), minute: t.Minute(), second: t.Second(), nanos: t.Nanosecond(),
+	}
+	valid := n >= 0
+	switch part {
+	case "YYYY":
+		f.year = n
+	case "MM":
+		f.month = n
+	case "DD":
+		f.day = n
+	case "HH":
+		f.hour = n
+	case "MI":
+		f.minute = n
+	case "SS":
+		f.second = n
+	case "MS":
+		f.nanos, valid = n*1e6, valid && n < 1e3
+	case "US":
+		f.nanos, valid = n*1e3, valid && n < 1e6
+	case "NS":
+		f.nanos, valid = n, valid && n < 1e9
+	}
+	// the error names the part that was set, rather than another part it made invalid such as the day of 31 when
+	// the month is set to April
+	date := formatDate(t, DefaultDateFormat)
+	t, dErr := f.date(date)
+	if !valid || dErr != nil {
+		err = &RowError{
+			Function: "SET_DATE_PART",
+			Message:  fmt.Sprintf("%d is not a valid value for %s of %s", n, format, date),
+		}
+		return
+	}
+	result = NewDate(t)
+
+	return
+}
+
+// lastDay returns the last day of the month of the date, keeping the time
+func lastDay(args ...Value) (result Value, err error) {
+	if args[0].IsNull() {
+		result = NewNull(TypeDate)
+		return
+	}
+
+	t, err := toTime("LAST_DAY", args[0])
+	if err != nil {
+		return
+	}
+	result = NewDate(t.AddDate(0, 0, daysIn(t.Month(), t.Year())-t.Day()))
+
+	return
+}
+
+// makeDateTime returns a date from its parts; the time parts are optional
+func makeDateTime(args ...Value) (result Value, err error) {
+	if anyNull(args) {
+		result = NewNull(TypeDate)
+		return
+	}
+
+	parts := make([]int, 7)
+	for i, arg := range args {
+		if parts[i], err = toPosition("MAKE_DATE_TIME", arg); err != nil {
+			return
+		}
+	}
+	if parts[6] < 0 || parts[6] >= 1e9 {
+		err = &RowError{Function: "MAKE_DATE_TIME", Message: fmt.Sprintf("%d is not a valid nanosecond", parts[6])}
+		return
+	}
+
+	f := dateFields{
+		year: parts[0], month: parts[1], day: parts[2],
+		hour: parts[3], minute: parts[4], second: parts[5], nanos: parts[6],
+	}
+	value := fmt.Sprintf("%02d/%02d/%04d %02d:%02d:%02d.%09d", f.month, f.day, f.year, f.hour, f.minute, f.second,
+		f.nanos)
+	t, dErr := f.date(value)
+	switch {
+	case dErr != nil:
+		err = &RowError{Function: "MAKE_DATE_TIME", Message: dErr.Error()}
+	case f.hour < 0 || f.minute < 0 || f.second < 0:
+		err = &RowError{Function: "MAKE_DATE_TIME", Message: fmt.Sprintf("'%s' is not a valid date", value)}
+	}
+	if err != nil {
+		return
+	}
+	result = NewDate(t)
+
+	return
+}
+
+// roundDate rounds (or truncates when up is false) a date to the date part given by format, DD by default
+// e.g. rounding to MM moves days 1 to 15 to the first of the month and later days to the first of the next month
+func roundDate(fn string, args []Value, up bool) (result Value, err error) {
+	if anyNull(args) {
+		result = NewNull(TypeDate)
+		return
+	}
+
+	t := args[0].t
+	part := "DD"
+	if len(args) > 1 {
+		format, fErr := toDateFormat(fn, args[1])
+		if fErr != nil {
+			err = fErr
+			return
+		}
+		if part, err = datePart(fn, format); err != nil {
+			return
+		}
+	}
+
+	var truncated, next time.Time
+	var half bool
+	switch part {
+	case "YYYY":
+		truncated = time.Date(t.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+		next, half = truncated.AddDate(1, 0, 0), t.Month() >= time.July
+	case "MM":
+		truncated = time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+		next, half = truncated.AddDate(0, 1, 0), t.Day() >= 16
+	case "DD":
+		truncated = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+		next, half = truncated.AddDate(0, 0, 1), t.Hour() >= 12
+	default:
+		unit := partDuration[part]
+		truncated = t.Truncate(unit)
+		next, half = truncated.Add(unit), t.Sub(truncated) >= unit/2
+	}
+
+	if up && half {
+		result, err = checkDate(fn, next)
+		return
+	}
+	result = NewDate(truncated)
+
+	return
+}
+
+// sysTimestamp returns the current date and time to the precision given by format (SS, MS, US or NS), US by default
+func sysTimestamp(env *environment, args ...Value) (result Value, err error) {
+	precision := "US"
+	if len(args) > 0 && !args[0].IsNull() {
+		format, fErr := toDateFormat("SYSTIMESTAMP", args[0])
+		if fErr != nil {
+			err = fErr
+			return
+		}
+		if precision, err = datePart("SYSTIMESTAMP", format); err != nil {
+			return
+		}
+	}
+
+	unit, ok := partDuration[precision]
+	if !ok || unit > time.Second {
+		err = &RowError{Function: "SYSTIMESTAMP", Message: fmt.Sprintf("'%s' is not a valid precision", precision)}
+		return
+	}
+	result = NewDate(NewDate(env.now).t.Truncate(unit))
+
+	return
+}
+
+// toChar converts a date to a string in the format, the default date format when it is omitted
+// Strings are returned as is and numbers are rendered by formatNumber
+func toChar(args ...Value) (result Value, err error) {
+	if anyNull(args) {
+		result = NewNull(TypeString)
+		return
+	}
+
+	if args[0].typ.IsNumeric() {
+		result = NewString(formatNumber(args[0]))
+		return
+	}
+	if args[0].typ != TypeDate {
+		result = NewString(toText(args[0]))
+		return
+	}
+
+	format := DefaultDateFormat
+	if len(args) > 1 {
+		if format, err = toDateFormat("TO_CHAR", args[1]); err != nil {
+			return
+		}
+	}
+	s, fErr := formatDateWith(args[0].t, format)
+	if fErr != nil {
+		err = &RowError{Function: "TO_CHAR", Message: fErr.Error()}
+		return
+	}
+	result = NewString(s)
+
+	return
+}
+
+// toDate converts a string to a date in the format, or the default date format when it is omitted
+// A string which doesn't match the format is a row error
+func toDate(env *environment, args ...Value) (result Value, err error) {
+	if anyNull(args) {
+		result = NewNull(TypeDate)
+		return
+	}
+
+	t, pErr := parseDateArgs(env, "TO_DATE", args)
+	if pErr != nil {
+		err = &RowError{Function: "TO_DATE", Message: pErr.Error()}
+		return
+	}
+	result = NewDate(t)
+
+	return
+}
+
+// isDate returns TRUE (1) if the string is a date in the format, or the default date format when it is omitted
+func isDate(env *environment, args ...Value) (result Value, err error) {
+	if anyNull(args) {
+		result = NewNull(TypeInteger)
+		return
+	}
+
+	_, pErr := parseDateArgs(env, "IS_DATE", args)
+	result = boolValue(pErr == nil)
+
+	return
+}
+
+// parseDateArgs reads the date in the string and optional format arguments of TO_DATE and IS_DATE
+func parseDateArgs(env *environment, fn string, args []Value) (t time.Time, err error) {
+	value := toText(args[0])
+	if len(args) == 1 {
+		return parseDefaultDate(value)
+	}
+
+	format, err := toDateFormat(fn, args[1])
+	if err != nil {
+		return
+	}
+	now := NewDate(env.now).t
+
+	return parseDateWith(value, format, now)
+}
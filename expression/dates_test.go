@@ -0,0 +1,263 @@
+package expression
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// dateEvaluator has a fixed SYSDATE so that the RR and YY century rules are repeatable
+var dateEvaluator, _ = NewEvaluator(Options{
+	Now: func() time.Time { return time.Date(2021, 6, 15, 10, 30, 45, 123456789, time.UTC) },
+})
+
+func runDateTests(t *testing.T, testCases []stringTestCase) {
+	t.Helper()
+	vars := []Variable{
+		{N: "d", T: "DATE", V: "03/29/1997 12:00:00"},
+		{N: "d2", T: "DATE", V: "01/01/1997"},
+	}
+
+	for _, tc := range testCases {
+		result, err := dateEvaluator.Evaluate(tc.input, vars)
+		if err != nil {
+			t.Errorf("Input: %s\nUnexpected error: %v", tc.input, err)
+			continue
+		}
+
+		if result.String() != tc.expect {
+			t.Errorf("Input: %s\nExpected: `%s`, got `%s`", tc.input, tc.expect, result.String())
+		}
+	}
+}
+
+func TestTODATE(t *testing.T) {
+	runDateTests(t, []stringTestCase{
+		{`TO_DATE('04/01/1998 12:15:30')`, `04/01/1998 12:15:30.000000`},
+		{`TO_DATE('04/01/1998')`, `04/01/1998 00:00:00.000000`},
+		{`TO_DATE('04/01/1998 12:15:30.123456')`, `04/01/1998 12:15:30.123456`},
+		{`TO_DATE('20200105', 'YYYYMMDD')`, `01/05/2020 00:00:00.000000`},
+		{`TO_DATE('1/5/2020', 'MM/DD/YYYY')`, `01/05/2020 00:00:00.000000`},
+		{`TO_DATE('jan 5 2020', 'MON DD YYYY')`, `01/05/2020 00:00:00.000000`},
+		{`TO_DATE('January 5, 2020', 'MONTH DD, YYYY')`, `01/05/2020 00:00:00.000000`},
+		{`TO_DATE('Sun 05-Jan-2020', 'DY DD-MON-YYYY')`, `01/05/2020 00:00:00.000000`},
+		{`TO_DATE('05/01/98', 'DD/MM/RR')`, `01/05/1998 00:00:00.000000`},
+		{`TO_DATE('05/01/21', 'DD/MM/RR')`, `01/05/2021 00:00:00.000000`},
+		{`TO_DATE('05/01/49', 'DD/MM/RR')`, `01/05/2049 00:00:00.000000`},
+		{`TO_DATE('05/01/50', 'DD/MM/RR')`, `01/05/1950 00:00:00.000000`},
+		{`TO_DATE('05/01/98', 'DD/MM/YY')`, `01/05/2098 00:00:00.000000`},
+		{`TO_DATE('3:15:30 PM', 'HH:MI:SS AM')`, `01/01/0001 15:15:30.000000`},
+		{`TO_DATE('12:00 a.m.', 'HH12:MI A.M.')`, `01/01/0001 00:00:00.000000`},
+		{`TO_DATE('2020-01-05 23:59:59.5', 'YYYY-MM-DD HH24:MI:SS.MS')`, `01/05/2020 23:59:59.500000`},
+		{`TO_DATE('2020-01-05 00:00:00.000000001', 'YYYY-MM-DD HH24:MI:SS.NS')`, `01/05/2020 00:00:00.000000`},
+		{`TO_DATE('2451545', 'J')`, `01/01/2000 00:00:00.000000`},
+		{`TO_DATE('2020 060', 'YYYY DDD')`, `02/29/2020 00:00:00.000000`},
+		{`TO_DATE('2020T43200', 'YYYY"T"SSSSS')`, `01/01/2020 12:00:00.000000`},
+		{`TO_DATE(NULL)`, `NULL`},
+		{`TO_DATE('01/01/2020', NULL)`, `NULL`},
+	})
+}
+
+func TestTODATEErrors(t *testing.T) {
+	testCases := []string{
+		`TO_DATE('02/30/2020')`,
+		`TO_DATE('13/01/2020')`,
+		`TO_DATE('01/01/2020 24:00:00')`,
+		`TO_DATE('2020-01-01')`,
+		`TO_DATE('01/01/2020x', 'MM/DD/YYYY')`,
+		`TO_DATE('01/01/2020', 'MM/DD/YYYY BAD')`,
+		`TO_DATE('2020', 'Q')`,
+		`TO_DATE('0', 'YYYY')`,
+	}
+
+	for _, tc := range testCases {
+		_, err := dateEvaluator.Evaluate(tc, nil)
+		if _, ok := err.(*RowError); !ok {
+			t.Errorf("Input: %s\nExpected a *RowError, got `%v`", tc, err)
+		}
+	}
+}
+
+func TestISDATE(t *testing.T) {
+	runDateTests(t, []stringTestCase{
+		{`IS_DATE('02/29/2020')`, `1`},
+		{`IS_DATE('02/29/2021')`, `0`},
+		{`IS_DATE('2021-02-28', 'YYYY-MM-DD')`, `1`},
+		{`IS_DATE('2021-02-28', 'MM/DD/YYYY')`, `0`},
+		{`IS_DATE('2021-02-28', 'BAD')`, `0`},
+		{`IS_DATE(NULL)`, `NULL`},
+	})
+}
+
+func TestTOCHAR(t *testing.T) {
+	runDateTests(t, []stringTestCase{
+		{`TO_CHAR(d)`, `03/29/1997 12:00:00.000000`},
+		{`TO_CHAR(d, 'MM/DD/YYYY')`, `03/29/1997`},
+		{`TO_CHAR(d, 'mon dd yyyy')`, `Mar 29 1997`},
+		{`TO_CHAR(d, 'DAY, MONTH DD')`, `Saturday, March 29`},
+		{`TO_CHAR(d, 'DY D DDD')`, `Sat 7 088`},
+		{`TO_CHAR(d, 'YYY YY Y RR')`, `997 97 7 97`},
+		{`TO_CHAR(d, 'HH12:MI AM')`, `12:00 PM`},
+		{`TO_CHAR(d2, 'HH:MI:SS A.M.')`, `12:00:00 A.M.`},
+		{`TO_CHAR(d, 'SSSSS')`, `43200`},
+		{`TO_CHAR(d, 'J')`, `2450537`},
+		{`TO_CHAR(d, 'Q W WW')`, `1 5 13`},
+		{`TO_CHAR(d, '"Quarter" Q')`, `Quarter 1`},
+		{`TO_CHAR(SYSDATE, 'HH24:MI:SS.MS.US.NS')`, `10:30:45.123.123456.123456789`},
+		{`TO_CHAR(NULL)`, `NULL`},
+		{`TO_CHAR(d, NULL)`, `NULL`},
+		{`TO_CHAR('abc')`, `abc`},
+	})
+}
+
+func TestADDTODATE(t *testing.T) {
+	runDateTests(t, []stringTestCase{
+		{`ADD_TO_DATE(d, 'YYYY', 1)`, `03/29/1998 12:00:00.000000`},
+		{`ADD_TO_DATE(d, 'RR', -10)`, `03/29/1987 12:00:00.000000`},
+		{`ADD_TO_DATE(d, 'MM', 1)`, `04/29/1997 12:00:00.000000`},
+		{`ADD_TO_DATE(TO_DATE('01/31/2020'), 'MON', 1)`, `02/29/2020 00:00:00.000000`},
+		{`ADD_TO_DATE(TO_DATE('02/29/2020'), 'YY', 1)`, `02/28/2021 00:00:00.000000`},
+		{`ADD_TO_DATE(TO_DATE('03/31/2020'), 'MM', -1)`, `02/29/2020 00:00:00.000000`},
+		{`ADD_TO_DATE(d, 'DD', 3)`, `04/01/1997 12:00:00.000000`},
+		{`ADD_TO_DATE(d, 'DDD', -29)`, `02/28/1997 12:00:00.000000`},
+		{`ADD_TO_DATE(d, 'HH', 14)`, `03/30/1997 02:00:00.000000`},
+		{`ADD_TO_DATE(d, 'MI', -90)`, `03/29/1997 10:30:00.000000`},
+		{`ADD_TO_DATE(d, 'SS', 59)`, `03/29/1997 12:00:59.000000`},
+		{`ADD_TO_DATE(d, 'MS', 1500)`, `03/29/1997 12:00:01.500000`},
+		{`ADD_TO_DATE(d, 'US', 7)`, `03/29/1997 12:00:00.000007`},
+		{`ADD_TO_DATE(d, 'NS', 1000)`, `03/29/1997 12:00:00.000001`},
+		{`ADD_TO_DATE(NULL, 'DD', 1)`, `NULL`},
+		{`ADD_TO_DATE(d, 'DD', NULL)`, `NULL`},
+		// amounts of hours or minutes longer than a Duration holds
+		{`ADD_TO_DATE(TO_DATE('2000-01-01', 'YYYY-MM-DD'), 'HH', 2600000)`, `08/09/2296 08:00:00.000000`},
+		{`ADD_TO_DATE(TO_DATE('2000-01-01', 'YYYY-MM-DD'), 'MI', 200000000)`, `04/06/2380 21:20:00.000000`},
+		{`ADD_TO_DATE(TO_DATE('2000-01-01', 'YYYY-MM-DD'), 'SS', -2000000000)`, `08/15/1936 20:26:40.000000`},
+	})
+
+	for _, input := range []string{
+		`ADD_TO_DATE(TO_DATE('12/31/9999'), 'DD', 1)`,
+		`ADD_TO_DATE(TO_DATE('2000-01-01', 'YYYY-MM-DD'), 'HH', 2000000000)`,
+		`ADD_TO_DATE(TO_DATE('2000-01-01', 'YYYY-MM-DD'), 'MI', -2000000000)`,
+	} {
+		_, err := Evaluate(input, nil)
+		if _, ok := err.(*RowError); !ok {
+			t.Errorf("Input: %s\nExpected a *RowError for a date out of range, got `%v`", input, err)
+		}
+	}
+}
+
+func TestDATEDIFF(t *testing.T) {
+	runDateTests(t, []stringTestCase{
+		{`DATE_DIFF(d2, d, 'HH')`, `-2100`},
+		{`DATE_DIFF(d2, d, 'DD')`, `-87.5`},
+		{`DATE_DIFF(d, d2, 'DDD')`, `87.5`},
+		{`DATE_DIFF(d2, d, 'MM')`, `-2.91935483870968`},
+		{`DATE_DIFF(d2, d, 'YYYY')`, `-0.243279569892473`},
+		{`DATE_DIFF(d, d2, 'MI')`, `126000`},
+		{`DATE_DIFF(d, d2, 'SS')`, `7560000`},
+		{`DATE_DIFF(d, ADD_TO_DATE(d, 'US', -1), 'MS')`, `0.001`},
+		{`DATE_DIFF(TO_DATE('12/31/9999'), TO_DATE('01/01/0001'), 'YYYY')`, `9998.99731182796`},
+		{`DATE_DIFF(d, NULL, 'DD')`, `NULL`},
+	})
+}
+
+func TestDATECOMPARE(t *testing.T) {
+	runDateTests(t, []stringTestCase{
+		{`DATE_COMPARE(d, d2)`, `1`},
+		{`DATE_COMPARE(d2, d)`, `-1`},
+		{`DATE_COMPARE(d, d)`, `0`},
+		{`DATE_COMPARE(d, NULL)`, `NULL`},
+	})
+}
+
+func TestDATEPARTS(t *testing.T) {
+	runDateTests(t, []stringTestCase{
+		{`GET_DATE_PART(d, 'YYYY')`, `1997`},
+		{`GET_DATE_PART(d, 'MON')`, `3`},
+		{`GET_DATE_PART(d, 'DDD')`, `29`},
+		{`GET_DATE_PART(d, 'HH24')`, `12`},
+		{`GET_DATE_PART(SYSDATE, 'MI')`, `30`},
+		{`GET_DATE_PART(SYSDATE, 'SS')`, `45`},
+		{`GET_DATE_PART(SYSDATE, 'MS')`, `123`},
+		{`GET_DATE_PART(SYSDATE, 'US')`, `123456`},
+		{`GET_DATE_PART(SYSDATE, 'NS')`, `123456789`},
+		{`GET_DATE_PART(NULL, 'YYYY')`, `NULL`},
+		{`SET_DATE_PART(d, 'YYYY', 2000)`, `03/29/2000 12:00:00.000000`},
+		{`SET_DATE_PART(d, 'MM', 4)`, `04/29/1997 12:00:00.000000`},
+		{`SET_DATE_PART(d, 'DD', 1)`, `03/01/1997 12:00:00.000000`},
+		{`SET_DATE_PART(d, 'HH', 0)`, `03/29/1997 00:00:00.000000`},
+		{`SET_DATE_PART(d, 'MI', 59)`, `03/29/1997 12:59:00.000000`},
+		{`SET_DATE_PART(d, 'MS', 250)`, `03/29/1997 12:00:00.250000`},
+		{`SET_DATE_PART(d, 'YYYY', NULL)`, `NULL`},
+		{`LAST_DAY(d)`, `03/31/1997 12:00:00.000000`},
+		{`LAST_DAY(TO_DATE('02/10/2020'))`, `02/29/2020 00:00:00.000000`},
+		{`LAST_DAY(NULL)`, `NULL`},
+		{`MAKE_DATE_TIME(2020, 2, 29)`, `02/29/2020 00:00:00.000000`},
+		{`MAKE_DATE_TIME(2020, 2, 29, 13, 14, 15, 16000)`, `02/29/2020 13:14:15.000016`},
+		{`MAKE_DATE_TIME(2020, NULL, 29)`, `NULL`},
+	})
+
+	for _, tc := range []string{
+		`SET_DATE_PART(d, 'DD', 30)`,
+		`SET_DATE_PART(TO_DATE('03/29/1997'), 'MM', 2)`,
+		`SET_DATE_PART(d, 'MM', 13)`,
+		`SET_DATE_PART(d, 'HH', 24)`,
+		`SET_DATE_PART(d, 'DD', -1)`,
+		`GET_DATE_PART(d, 'XX')`,
+		`MAKE_DATE_TIME(2021, 2, 29)`,
+		`MAKE_DATE_TIME(2021, 1, 1, -1)`,
+	} {
+		_, err := dateEvaluator.Evaluate(tc, []Variable{{N: "d", T: "DATE", V: "02/10/2020"}})
+		if _, ok := err.(*RowError); !ok {
+			t.Errorf("Input: %s\nExpected a *RowError, got `%v`", tc, err)
+		}
+	}
+
+	// the messages name the part and the value which made the date invalid
+	for _, tc := range []stringTestCase{
+		{`SET_DATE_PART(TO_DATE('01/31/2021'), 'MM', 4)`,
+			`4 is not a valid value for MM of 01/31/2021 00:00:00.000000`},
+		{`SET_DATE_PART(TO_DATE('01/31/2021'), 'HH', 24)`,
+			`24 is not a valid value for HH of 01/31/2021 00:00:00.000000`},
+		{`MAKE_DATE_TIME(2023, 2, 29)`, `'02/29/2023 00:00:00.000000000' has an invalid day 29`},
+		{`MAKE_DATE_TIME(2023, 1, 1, 0, -5)`, `'01/01/2023 00:-5:00.000000000' is not a valid date`},
+	} {
+		_, err := dateEvaluator.Evaluate(tc.input, nil)
+		if err == nil || !strings.Contains(err.Error(), tc.expect) {
+			t.Errorf("Input: %s\nExpected an error containing `%s`, got `%v`", tc.input, tc.expect, err)
+		}
+	}
+}
+
+func TestROUNDTRUNCDates(t *testing.T) {
+	runDateTests(t, []stringTestCase{
+		{`ROUND(d)`, `03/30/1997 00:00:00.000000`},
+		{`ROUND(d, 'YYYY')`, `01/01/1997 00:00:00.000000`},
+		{`ROUND(TO_DATE('07/01/1997'), 'YY')`, `01/01/1998 00:00:00.000000`},
+		{`ROUND(d, 'MM')`, `04/01/1997 00:00:00.000000`},
+		{`ROUND(TO_DATE('03/15/1997 23:59:59'), 'MM')`, `03/01/1997 00:00:00.000000`},
+		{`ROUND(TO_DATE('03/29/1997 11:59:59'), 'DD')`, `03/29/1997 00:00:00.000000`},
+		{`ROUND(TO_DATE('03/29/1997 11:30:00'), 'HH')`, `03/29/1997 12:00:00.000000`},
+		{`ROUND(TO_DATE('03/29/1997 11:29:29'), 'MI')`, `03/29/1997 11:29:00.000000`},
+		{`ROUND(SYSDATE, 'SS')`, `06/15/2021 10:30:45.000000`},
+		{`ROUND(SYSDATE, 'MS')`, `06/15/2021 10:30:45.123000`},
+		{`ROUND(SYSDATE, 'US')`, `06/15/2021 10:30:45.123457`},
+		{`TRUNC(d)`, `03/29/1997 00:00:00.000000`},
+		{`TRUNC(d, 'Y')`, `01/01/1997 00:00:00.000000`},
+		{`TRUNC(d, 'MONTH')`, `03/01/1997 00:00:00.000000`},
+		{`TRUNC(SYSDATE, 'HH')`, `06/15/2021 10:00:00.000000`},
+		{`TRUNC(SYSDATE, 'MI')`, `06/15/2021 10:30:00.000000`},
+		{`TRUNC(SYSDATE, 'US')`, `06/15/2021 10:30:45.123456`},
+		{`ROUND(TO_DATE(NULL))`, `NULL`},
+		{`TRUNC(d, NULL)`, `NULL`},
+	})
+}
+
+func TestSYSTIMESTAMP(t *testing.T) {
+	runDateTests(t, []stringTestCase{
+		{`TO_CHAR(SYSTIMESTAMP(), 'NS')`, `123456000`},
+		{`TO_CHAR(SYSTIMESTAMP('SS'), 'NS')`, `000000000`},
+		{`TO_CHAR(SYSTIMESTAMP('MS'), 'NS')`, `123000000`},
+		{`TO_CHAR(SYSTIMESTAMP('NS'), 'NS')`, `123456789`},
+	})
+}
@@ -0,0 +1,195 @@
+// decimals are held exactly, as their unscaled digits and a scale, so that high precision arithmetic doesn't lose
+// digits to floating point
+
+package expression
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// maxDecimalPrecision is the largest precision of a decimal
+const maxDecimalPrecision = 38
+
+var bigTen = big.NewInt(10)
+
+// ParseDecimal converts the text of a number (e.g. '1234567890123456789012.345678') to a decimal without going
+// through a float, keeping the scale of the text; use it for values of decimal ports with more than 15 digits
+func ParseDecimal(text string) (v Value, err error) {
+	text = strings.TrimSpace(text)
+	if !numberPattern.MatchString(text) {
+		err = fmt.Errorf("'%s' is not a number", text)
+		return
+	}
+
+	r, _ := new(big.Rat).SetString(text)
+	scale := 0
+	if dot := strings.Index(text, "."); dot >= 0 && !strings.ContainsAny(text, "eE") {
+		scale = len(text) - dot - 1
+	} else {
+		scale = ratScale(r)
+	}
+
+	return exactDecimal(r, scale)
+}
+
+// exactDecimal returns a decimal holding r rounded half away from zero to scale digits after the decimal point
+// When the result would have more than maxDecimalPrecision digits the scale is reduced, but a number whose integer
+// part alone has too many digits is an error
+func exactDecimal(r *big.Rat, scale int) (v Value, err error) {
+	if scale > maxDecimalPrecision {
+		scale = maxDecimalPrecision
+	}
+	d := roundRat(r, scale, true)
+	if excess := len(new(big.Int).Abs(d).String()) - maxDecimalPrecision; excess > 0 {
+		if excess > scale {
+			err = fmt.Errorf("%s has more than %d digits", r.FloatString(0), maxDecimalPrecision)
+			return
+		}
+		scale -= excess
+		d = roundRat(r, scale, true)
+	}
+
+	precision := len(new(big.Int).Abs(d).String())
+	if precision < scale {
+		precision = scale
+	}
+	f, _ := new(big.Rat).SetFrac(d, pow10(scale)).Float64()
+	v = Value{typ: TypeDecimal, f: f, d: d, precision: precision, scale: scale}
+
+	return
+}
+
+// rat returns the exact value of a number; doubles are taken as the shortest decimal which is the double
+func (v Value) rat() *big.Rat {
+	switch v.typ {
+	case TypeInteger, TypeBigint:
+		return new(big.Rat).SetInt64(v.i)
+	case TypeDecimal:
+		if v.d == nil {
+			return new(big.Rat)
+		}
+		return new(big.Rat).SetFrac(v.d, pow10(v.scale))
+	}
+
+	r, ok := new(big.Rat).SetString(strconv.FormatFloat(v.f, 'g', -1, 64))
+	if !ok {
+		return new(big.Rat)
+	}
+
+	return r
+}
+
+// ratScale returns the number of digits after the decimal point that r needs, up to the maximum precision
+func ratScale(r *big.Rat) int {
+	scaled := new(big.Rat).Set(r)
+	for scale := 0; scale < maxDecimalPrecision; scale++ {
+		if scaled.IsInt() {
+			return scale
+		}
+		scaled.Mul(scaled, big.NewRat(10, 1))
+	}
+
+	return maxDecimalPrecision
+}
+
+// formatDecimal renders unscaled digits d with scale digits after the decimal point, e.g. 1250 with a scale of 2 is
+// 12.50
+func formatDecimal(d *big.Int, scale int) string {
+	if d == nil {
+		d = new(big.Int)
+	}
+	digits := new(big.Int).Abs(d).String()
+	if scale > 0 {
+		if len(digits) <= scale {
+			digits = strings.Repeat("0", scale-len(digits)+1) + digits
+		}
+		digits = digits[:len(digits)-scale] + "." + digits[len(digits)-scale:]
+	}
+	if d.Sign() < 0 {
+		digits = "-" + digits
+	}
+
+	return digits
+}
+
+// decimalArithmetic applies an operator to two numbers exactly when at least one is a decimal and neither is a double
+// Sums and differences keep the larger scale and products add the scales; quotients have as many digits as a
+// decimal can hold, less any trailing zeros beyond the larger scale of the operands
+func decimalArithmetic(op string, a Value, b Value) (result Value, err error) {
+	x, y := a.rat(), b.rat()
+	scale := a.decimalScale()
+	if b.decimalScale() > scale {
+		scale = b.decimalScale()
+	}
+
+	r := new(big.Rat)
+	switch op {
+	case "+":
+		r.Add(x, y)
+	case "-":
+		r.Sub(x, y)
+	case "*":
+		r.Mul(x, y)
+		scale = a.decimalScale() + b.decimalScale()
+	case "/", "%":
+		if y.Sign() == 0 {
+			err = &RowError{Function: op, Message: "divide by zero"}
+			return
+		}
+		r.Quo(x, y)
+		if op == "%" {
+			// the remainder has the sign of the dividend, as truncating division leaves it
+			q := new(big.Int).Quo(r.Num(), r.Denom())
+			r.Sub(x, new(big.Rat).Mul(y, new(big.Rat).SetInt(q)))
+			break
+		}
+		minScale := scale
+		scale = maxDecimalPrecision
+		if whole := new(big.Int).Quo(new(big.Int).Abs(r.Num()), r.Denom()); whole.Sign() != 0 {
+			scale -= len(whole.String())
+		}
+		if scale < minScale {
+			scale = minScale
+		}
+		if result, err = exactDecimal(r, scale); err != nil {
+			err = &RowError{Function: op, Message: err.Error()}
+			return
+		}
+		result = trimDecimal(result, minScale)
+		return
+	default:
+		err = fmt.Errorf("the operator %s isn't defined for decimals", op)
+		return
+	}
+
+	if result, err = exactDecimal(r, scale); err != nil {
+		err = &RowError{Function: op, Message: err.Error()}
+	}
+
+	return
+}
+
+// trimDecimal removes trailing zeros after the decimal point down to minScale digits, e.g. 2.000 becomes 2.0 with a
+// minScale of 1
+func trimDecimal(v Value, minScale int) Value {
+	d := new(big.Int).Set(v.d)
+	mod := new(big.Int)
+	for v.scale > minScale {
+		q, m := new(big.Int).QuoRem(d, bigTen, mod)
+		if m.Sign() != 0 {
+			break
+		}
+		d = q
+		v.scale--
+	}
+	v.d = d
+	v.precision = len(new(big.Int).Abs(d).String())
+	if v.precision < v.scale {
+		v.precision = v.scale
+	}
+
+	return v
+}
@@ -0,0 +1,103 @@
+package expression
+
+import (
+	"testing"
+)
+
+func TestHighPrecision(t *testing.T) {
+	high := newEvaluator(t, Options{HighPrecision: true})
+	testCases := []struct {
+		input string
+		low   string // the result with high precision disabled, the default
+		high  string
+	}{
+		{`0.1 + 0.2`, `0.3`, `0.3`},
+		{`2 * 3.5`, `7`, `7.0`},
+		{`1234567890123456789.123456789 + 1`, `1.23456789012346e+18`, `1234567890123456790.123456789`},
+		{`12345678901234567890123456.78 * 100`, `1.23456789012346e+27`, `1234567890123456789012345678.00`},
+		{`1.0 / 3`, `0.333333333333333`, `0.33333333333333333333333333333333333333`},
+		{`2.0 / 3`, `0.666666666666667`, `0.66666666666666666666666666666666666667`},
+		{`6.0 / 3`, `2`, `2.0`},
+		{`10 / 4.00`, `2.5`, `2.50`},
+		{`5.5 % 2`, `1.5`, `1.5`},
+		{`-5.5 % 2`, `-1.5`, `-1.5`},
+		{`-(12345678901234567890.5)`, `-1.23456789012346e+19`, `-12345678901234567890.5`},
+		{`ABS(-12345678901234567890.5)`, `1.23456789012346e+19`, `12345678901234567890.5`},
+		{`12345678901234567890.01 > 12345678901234567890`, `0`, `1`},
+		{`ROUND(12.99, 1)`, `13`, `13.0`},
+		{`ROUND(1234567890123456789.125, 2)`, `1.23456789012346e+18`, `1234567890123456789.13`},
+		{`TRUNC(-1567.99, -2)`, `-1500`, `-1500`},
+		{`CEIL(-39.79)`, `-39`, `-39`},
+		{`FLOOR(12345678901234567890.5)`, `1.23456789012346e+19`, `12345678901234567890`},
+		{`TO_DECIMAL('60.2', 3)`, `60.2`, `60.200`},
+		{`TO_DECIMAL('12345678901234567890.12545', 3)`, `1.23456789012346e+19`, `12345678901234567890.125`},
+		{`TO_CHAR(123.450)`, `123.45`, `123.450`},
+		{`IIF(TRUE, 1.50, 2)`, `1.5`, `1.50`},
+		{`99999999999999999999999999999999999999 * 10`, `1e+39`, ``},
+	}
+
+	for _, tc := range testCases {
+		result, err := Evaluate(tc.input, nil)
+		if err != nil {
+			t.Errorf("Input: %s\nUnexpected error: %v", tc.input, err)
+		} else if result.String() != tc.low {
+			t.Errorf("Input: %s\nExpected: `%s`, got `%s`", tc.input, tc.low, result.String())
+		}
+
+		result, err = high.Evaluate(tc.input, nil)
+		if tc.high == "" {
+			if _, ok := err.(*RowError); !ok {
+				t.Errorf("Input: %s\nExpected a *RowError with high precision, got `%v`", tc.input, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Input: %s\nUnexpected error with high precision: %v", tc.input, err)
+		} else if result.String() != tc.high {
+			t.Errorf("Input: %s\nExpected with high precision: `%s`, got `%s`", tc.input, tc.high, result.String())
+		}
+	}
+}
+
+func TestParseDecimal(t *testing.T) {
+	testCases := []struct {
+		input     string
+		expect    string
+		precision int
+		scale     int
+	}{
+		{`1234567890123456789012345678.90`, `1234567890123456789012345678.90`, 30, 2},
+		{` -0.050 `, `-0.050`, 3, 3},
+		{`1.5e3`, `1500`, 4, 0},
+		{`+7`, `7`, 1, 0},
+	}
+
+	for _, tc := range testCases {
+		v, err := ParseDecimal(tc.input)
+		if err != nil {
+			t.Errorf("Input: %s\nUnexpected error: %v", tc.input, err)
+			continue
+		}
+		if v.String() != tc.expect || v.Precision() != tc.precision || v.Scale() != tc.scale {
+			t.Errorf("Input: %s\nExpected: `%s` (%d, %d), got `%s` (%d, %d)", tc.input, tc.expect, tc.precision,
+				tc.scale, v.String(), v.Precision(), v.Scale())
+		}
+	}
+
+	for _, input := range []string{`abc`, `1e400`} {
+		if _, err := ParseDecimal(input); err == nil {
+			t.Errorf("Input: %s\nExpected an error", input)
+		}
+	}
+
+	// a decimal port keeps all of its digits in a program run with high precision
+	program, err := newEvaluator(t, Options{HighPrecision: true}).Compile(`in_PRICE * 2`, programSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	price, _ := ParseDecimal(`1234567890123456789.05`)
+	result, err := program.Run(Row{"in_PRICE": price})
+	if err != nil || result.String() != `2469135780246913578.10` {
+		t.Errorf("Expected: `2469135780246913578.10`, got `%s` (%v)", result, err)
+	}
+}
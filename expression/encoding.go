@@ -0,0 +1,202 @@
+// hashing, encoding and compression functions
+// Strings are converted to bytes in the code page of the evaluator first, so the same string hashes differently in
+// UTF-8 and ISO-8859-1 when it has characters outside ASCII, as it does in PowerCenter
+
+package expression
+
+import (
+	"bytes"
+	"compress/zlib"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"strings"
+)
+
+// hashFunction returns a function which hashes its argument and returns the digest as lowercase hex digits
+func hashFunction(sum func(b []byte) []byte) func(env *environment, args ...Value) (Value, error) {
+	return func(env *environment, args ...Value) (result Value, err error) {
+		if args[0].IsNull() {
+			result = NewNull(TypeString)
+			return
+		}
+
+		b, err := toBytes(env, args[0])
+		if err != nil {
+			return
+		}
+		result = NewString(hex.EncodeToString(sum(b)))
+
+		return
+	}
+}
+
+func md5Sum(b []byte) []byte {
+	sum := md5.Sum(b)
+	return sum[:]
+}
+
+func sha256Sum(b []byte) []byte {
+	sum := sha256.Sum256(b)
+	return sum[:]
+}
+
+// crc32Checksum returns the IEEE CRC-32 of the value as an unsigned number
+func crc32Checksum(env *environment, args ...Value) (result Value, err error) {
+	if args[0].IsNull() {
+		result = NewNull(TypeBigint)
+		return
+	}
+
+	b, err := toBytes(env, args[0])
+	if err != nil {
+		return
+	}
+	result = NewBigint(int64(crc32.ChecksumIEEE(b)))
+
+	return
+}
+
+// encBase64 encodes the value as standard base64 with padding
+func encBase64(env *environment, args ...Value) (result Value, err error) {
+	if args[0].IsNull() {
+		result = NewNull(TypeString)
+		return
+	}
+
+	b, err := toBytes(env, args[0])
+	if err != nil {
+		return
+	}
+	result = NewString(base64.StdEncoding.EncodeToString(b))
+
+	return
+}
+
+// decBase64 decodes base64 to a binary; text which isn't base64 is a row error
+func decBase64(args ...Value) (result Value, err error) {
+	if args[0].IsNull() {
+		result = NewNull(TypeBinary)
+		return
+	}
+
+	b, dErr := base64.StdEncoding.DecodeString(strings.TrimSpace(toText(args[0])))
+	if dErr != nil {
+		err = &RowError{Function: "DEC_BASE64", Message: dErr.Error()}
+		return
+	}
+	result = NewBinary(b)
+
+	return
+}
+
+// encHex encodes the value as uppercase hex digits, the same as a binary is written to a string port
+func encHex(env *environment, args ...Value) (result Value, err error) {
+	if args[0].IsNull() {
+		result = NewNull(TypeString)
+		return
+	}
+
+	b, err := toBytes(env, args[0])
+	if err != nil {
+		return
+	}
+	result = NewString(strings.ToUpper(hex.EncodeToString(b)))
+
+	return
+}
+
+// decHex decodes hex digits in either case to a string in the code page of the evaluator
+func decHex(env *environment, args ...Value) (result Value, err error) {
+	if args[0].IsNull() {
+		result = NewNull(TypeString)
+		return
+	}
+
+	b, dErr := hex.DecodeString(strings.TrimSpace(toText(args[0])))
+	if dErr != nil {
+		err = &RowError{Function: "DEC_HEX", Message: dErr.Error()}
+		return
+	}
+	cp, err := env.evaluator.codePage()
+	if err != nil {
+		return
+	}
+	result = NewString(cp.decode(b))
+
+	return
+}
+
+// compress compresses the value with zlib
+func compress(env *environment, args ...Value) (result Value, err error) {
+	if args[0].IsNull() {
+		result = NewNull(TypeBinary)
+		return
+	}
+
+	b, err := toBytes(env, args[0])
+	if err != nil {
+		return
+	}
+	var buf bytes.Buffer
+	w := zlib.NewWriter(&buf)
+	if _, err = w.Write(b); err != nil {
+		return
+	}
+	if err = w.Close(); err != nil {
+		return
+	}
+	result = NewBinary(buf.Bytes())
+
+	return
+}
+
+// decompress reverses compress; the optional precision is the most bytes the result can have, and data which
+// wasn't compressed or which is longer than the precision is a row error
+func decompress(env *environment, args ...Value) (result Value, err error) {
+	if anyNull(args) {
+		result = NewNull(TypeBinary)
+		return
+	}
+
+	limit := -1
+	if len(args) > 1 {
+		if limit, err = toPosition("DECOMPRESS", args[1]); err != nil {
+			return
+		}
+	}
+
+	b, err := toBytes(env, args[0])
+	if err != nil {
+		return
+	}
+	r, zErr := zlib.NewReader(bytes.NewReader(b))
+	if zErr != nil {
+		err = &RowError{Function: "DECOMPRESS", Message: zErr.Error()}
+		return
+	}
+	var out io.Reader = r
+	if limit >= 0 {
+		out = io.LimitReader(r, int64(limit)+1)
+	}
+	data, zErr := ioutil.ReadAll(out)
+	if zErr != nil {
+		err = &RowError{Function: "DECOMPRESS", Message: zErr.Error()}
+		return
+	}
+	if limit >= 0 && len(data) > limit {
+		err = &RowError{
+			Function: "DECOMPRESS",
+			Message:  fmt.Sprintf("the decompressed value is longer than the precision %d", limit),
+		}
+		return
+	}
+	result = NewBinary(data)
+
+	return
+}
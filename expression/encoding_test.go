@@ -0,0 +1,93 @@
+package expression
+
+import (
+	"testing"
+)
+
+func TestHashes(t *testing.T) {
+	runStringTests(t, []stringTestCase{
+		{`MD5('Hello')`, `8b1a9953c4611296a827abf8c47804d7`},
+		{`MD5('café')`, `07117fe4a1ebd544965dc19573183da2`},
+		{`MD5('')`, `d41d8cd98f00b204e9800998ecf8427e`},
+		{`MD5(CONCAT('a', NULL))`, `0cc175b9c0f1b6a831c399e269772661`},
+		{`MD5(NULL)`, `NULL`},
+		{`SHA256('abc')`, `ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad`},
+		{`SHA256(NULL)`, `NULL`},
+		{`CRC32('abc')`, `891568578`},
+		{`CRC32('café')`, `2561491637`},
+		{`CRC32(NULL)`, `NULL`},
+	})
+}
+
+func TestEncodings(t *testing.T) {
+	runStringTests(t, []stringTestCase{
+		{`ENC_BASE64('Hello')`, `SGVsbG8=`},
+		{`ENC_BASE64('café')`, `Y2Fmw6k=`},
+		{`DEC_BASE64('SGVsbG8=')`, `48656C6C6F`},
+		{`ENC_BASE64(DEC_BASE64('Y2Fmw6k='))`, `Y2Fmw6k=`},
+		{`ENC_BASE64(NULL)`, `NULL`},
+		{`DEC_BASE64(NULL)`, `NULL`},
+		{`ENC_HEX('Hello')`, `48656C6C6F`},
+		{`DEC_HEX('48656c6c6f')`, `Hello`},
+		{`DEC_HEX(ENC_HEX('café'))`, `café`},
+		{`ENC_HEX(NULL)`, `NULL`},
+		{`ENC_HEX(DECOMPRESS(COMPRESS('Hello, Hello, Hello')))`, `48656C6C6F2C2048656C6C6F2C2048656C6C6F`},
+		{`MD5(DECOMPRESS(COMPRESS('café')))`, `07117fe4a1ebd544965dc19573183da2`},
+		{`ENC_HEX(DECOMPRESS(COMPRESS('Hello'), 5))`, `48656C6C6F`},
+		{`COMPRESS(NULL)`, `NULL`},
+		{`DECOMPRESS(NULL)`, `NULL`},
+		{`DEC_BASE64('not base64!')`, `<<Expression Error>> [DEC_BASE64]: illegal base64 data at input byte 3`},
+		{`DEC_HEX('XYZ')`, `<<Expression Error>> [DEC_HEX]: encoding/hex: invalid byte: U+0058 'X'`},
+		{`DECOMPRESS(DEC_BASE64('SGVsbG8='))`, `<<Expression Error>> [DECOMPRESS]: zlib: invalid header`},
+		{
+			`DECOMPRESS(COMPRESS('Hello'), 4)`,
+			`<<Expression Error>> [DECOMPRESS]: the decompressed value is longer than the precision 4`,
+		},
+	})
+}
+
+func TestCodePages(t *testing.T) {
+	testCases := []struct {
+		codePage string
+		input    string
+		expect   string
+	}{
+		{"ISO-8859-1", `MD5('café')`, `961f50f6282239d09e48f812c1ca7276`},
+		{"latin1", `CRC32('café')`, `2880679963`},
+		{"ISO-8859-1", `ENC_BASE64('café')`, `Y2Fm6Q==`},
+		{"ISO-8859-1", `DEC_HEX('636166E9')`, `café`},
+		{"ISO-8859-1", `ENC_HEX('€')`, `3F`},
+		{"MS1252", `ENC_HEX('€')`, `80`},
+		{"MS1252", `MD5('€100')`, `51468e83e528b11890f39018b1afa830`},
+		{"MS1252", `DEC_HEX('80')`, `€`},
+		{"US-ASCII", `ENC_HEX('café')`, `6361663F`},
+		{"", `ENC_HEX('é')`, `C3A9`},
+		{"UTF-8", `MD5('café')`, `07117fe4a1ebd544965dc19573183da2`},
+	}
+
+	for _, tc := range testCases {
+		result, err := newEvaluator(t, Options{CodePage: tc.codePage}).Evaluate(tc.input, nil)
+		if err != nil {
+			t.Errorf("Input: %s (%s)\nUnexpected error: %v", tc.input, tc.codePage, err)
+			continue
+		}
+		if result.String() != tc.expect {
+			t.Errorf("Input: %s (%s)\nExpected: `%s`, got `%s`", tc.input, tc.codePage, tc.expect, result.String())
+		}
+	}
+
+	// DECOMPRESS converts a string to bytes with the code page like the other functions
+	env := &environment{evaluator: newEvaluator(t, Options{CodePage: "ISO-8859-1"})}
+	compressed, err := compress(env, NewString("café"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := decompress(env, NewString(codePages["ISO-8859-1"].decode(compressed.b)))
+	if expect := "636166E9"; err != nil || result.String() != expect {
+		t.Errorf("Expected: `%s`, got `%s` (%v)", expect, result.String(), err)
+	}
+
+	if _, err := NewEvaluator(Options{CodePage: "EBCDIC"}); err == nil {
+		t.Error("Expected an error for an unsupported code page")
+	}
+}
@@ -0,0 +1,97 @@
+// encryption functions
+// These assume that PowerCenter encrypts with AES-128 in ECB mode with PKCS#7 padding, deriving the key the same way
+// as MySQL's AES_ENCRYPT: the bytes of the key are XORed into 16 bytes, so keys of any length can be used
+// This is unverified against PowerCenter: no ciphertext written by its AES_ENCRYPT has been checked, so values it
+// encrypted may not decrypt here
+
+package expression
+
+import (
+	"bytes"
+	"crypto/aes"
+)
+
+// aesKey folds the key into the 16 bytes of an AES-128 key; byte i of the key is XORed into byte i mod 16
+func aesKey(key []byte) []byte {
+	folded := make([]byte, aes.BlockSize)
+	for i, b := range key {
+		folded[i%aes.BlockSize] ^= b
+	}
+
+	return folded
+}
+
+// aesArgs returns the bytes of the value and the folded key, strings being converted with the evaluator's code page
+func aesArgs(env *environment, args []Value) (value []byte, key []byte, err error) {
+	if value, err = toBytes(env, args[0]); err != nil {
+		return
+	}
+	if key, err = toBytes(env, args[1]); err != nil {
+		return
+	}
+	key = aesKey(key)
+
+	return
+}
+
+// aesEncrypt encrypts the value with the key, returning a binary which is a whole number of blocks
+func aesEncrypt(env *environment, args ...Value) (result Value, err error) {
+	if anyNull(args) {
+		result = NewNull(TypeBinary)
+		return
+	}
+
+	value, key, err := aesArgs(env, args)
+	if err != nil {
+		return
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return
+	}
+
+	padding := aes.BlockSize - len(value)%aes.BlockSize
+	data := append(append([]byte(nil), value...), bytes.Repeat([]byte{byte(padding)}, padding)...)
+	for i := 0; i < len(data); i += aes.BlockSize {
+		block.Encrypt(data[i:i+aes.BlockSize], data[i:i+aes.BlockSize])
+	}
+	result = NewBinary(data)
+
+	return
+}
+
+// aesDecrypt decrypts a value encrypted with the key; a value which isn't a whole number of blocks or whose padding
+// is wrong, usually because the key is, is a row error
+func aesDecrypt(env *environment, args ...Value) (result Value, err error) {
+	if anyNull(args) {
+		result = NewNull(TypeBinary)
+		return
+	}
+
+	value, key, err := aesArgs(env, args)
+	if err != nil {
+		return
+	}
+	if len(value) == 0 || len(value)%aes.BlockSize != 0 {
+		err = &RowError{Function: "AES_DECRYPT", Message: "the value isn't a whole number of 16 byte blocks"}
+		return
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return
+	}
+
+	data := make([]byte, len(value))
+	for i := 0; i < len(data); i += aes.BlockSize {
+		block.Decrypt(data[i:i+aes.BlockSize], value[i:i+aes.BlockSize])
+	}
+	padding := int(data[len(data)-1])
+	if padding == 0 || padding > aes.BlockSize ||
+		!bytes.Equal(data[len(data)-padding:], bytes.Repeat([]byte{byte(padding)}, padding)) {
+		err = &RowError{Function: "AES_DECRYPT", Message: "the value can't be decrypted with the key"}
+		return
+	}
+	result = NewBinary(data[:len(data)-padding])
+
+	return
+}
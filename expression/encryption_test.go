@@ -0,0 +1,43 @@
+package expression
+
+import (
+	"testing"
+)
+
+// The expected ciphertexts come from openssl enc -aes-128-ecb with the key folded as aesKey folds it, e.g.
+// printf 'Hello' | openssl enc -aes-128-ecb -nosalt -K 73656372657400000000000000000000
+// They check the AES mode and padding but not the key folding, which is assumed and unverified against PowerCenter
+func TestAESENCRYPT(t *testing.T) {
+	runStringTests(t, []stringTestCase{
+		{`AES_ENCRYPT('Hello', 'secret')`, `FF7E4CF9708183A1870513AE6416FD43`},
+		{`AES_ENCRYPT('', 'secret')`, `40CBBF790B8073B4DF503ACC4FF1495E`},
+		{`AES_ENCRYPT('exactly 16 bytes', 'key')`, `55F43E8ABD2DE6A72E064EDCC6F2AFA8C717530F41F320757B4AA1BFAF11C42E`},
+		{
+			`AES_ENCRYPT('4111-1111-1111-1111', 'a much longer key than sixteen bytes')`,
+			`C00A6946A3F8BB203A9C370700B4F40C56B6FC4DE99CF55016E92CC3376250CF`,
+		},
+		{`ENC_BASE64(AES_ENCRYPT('Hello', 'secret'))`, `/35M+XCBg6GHBROuZBb9Qw==`},
+		{`AES_ENCRYPT(NULL, 'secret')`, `NULL`},
+		{`AES_ENCRYPT('Hello', NULL)`, `NULL`},
+	})
+}
+
+func TestAESDECRYPT(t *testing.T) {
+	runStringTests(t, []stringTestCase{
+		{`AES_DECRYPT(DEC_BASE64('/35M+XCBg6GHBROuZBb9Qw=='), 'secret')`, `48656C6C6F`},
+		{
+			`AES_DECRYPT(DEC_BASE64('wAppRqP4uyA6nDcHALT0DFa2/E3pnPVQFukswzdiUM8='), 'a much longer key than sixteen bytes')`,
+			`343131312D313131312D313131312D31313131`,
+		},
+		{`MD5(AES_DECRYPT(AES_ENCRYPT('café', 'k'), 'k'))`, `07117fe4a1ebd544965dc19573183da2`},
+		{`AES_DECRYPT(NULL, 'secret')`, `NULL`},
+		{
+			`AES_DECRYPT(DEC_BASE64('/35M+XCBg6GHBROuZBb9Qw=='), 'wrong')`,
+			`<<Expression Error>> [AES_DECRYPT]: the value can't be decrypted with the key`,
+		},
+		{
+			`AES_DECRYPT(DEC_BASE64('SGVsbG8='), 'secret')`,
+			`<<Expression Error>> [AES_DECRYPT]: the value isn't a whole number of 16 byte blocks`,
+		},
+	})
+}
@@ -0,0 +1,49 @@
+// errors returned while evaluating expressions
+
+package expression
+
+import "fmt"
+
+// RowError is an error that PowerCenter raises for a single row (e.g. dividing by zero).
+// The Integration Service skips the row and writes it to the session log rather than failing the session.
+type RowError struct {
+	Function string // the function or operator that raised the error
+	Message  string
+}
+
+func (e *RowError) Error() string {
+	return fmt.Sprintf("<<Expression Error>> [%s]: %s", e.Function, e.Message)
+}
+
+// AbortError is raised by the ABORT function; PowerCenter stops the session when it occurs
+type AbortError struct {
+	Message string
+}
+
+func (e *AbortError) Error() string {
+	return fmt.Sprintf("session aborted: %s", e.Message)
+}
+
+// Codes identifying the kind of a SyntaxError or Diagnostic
+const (
+	CodeSyntax            = "syntax"             // the input isn't a well formed expression
+	CodeUnknownIdentifier = "unknown-identifier" // a port, variable or parameter that hasn't been declared
+	CodeUnknownFunction   = "unknown-function"   // a call to a function that doesn't exist
+	CodeArgumentCount     = "argument-count"     // a function called with the wrong number of arguments
+	CodeArgumentType      = "argument-type"      // a function argument of the wrong datatype
+	CodeInvalidValue      = "invalid-value"      // a Variable whose value doesn't match its datatype
+	CodeNullComparison    = "null-comparison"    // a comparison with NULL, which is always NULL
+	CodeNotImplemented    = "not-implemented"    // a valid function that this library can't evaluate yet
+)
+
+// SyntaxError is an error in the input found while lexing or parsing, with the position of the offending token
+type SyntaxError struct {
+	Code    string // one of the Code constants
+	Message string
+	Token   string // the text of the offending token; empty when the end of the input was reached
+	Span    Span
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("syntax error at line %d, column %d: %s", e.Span.Start.Line, e.Span.Start.Column, e.Message)
+}
@@ -0,0 +1,131 @@
+// the evaluator holds the configuration that expressions are evaluated with
+
+package expression
+
+import "time"
+
+// Options configure an Evaluator, like the properties of a PowerCenter session
+type Options struct {
+	// Now returns the value of SYSDATE, which is read once for each evaluation; time.Now is used when it is nil
+	Now func() time.Time
+	// SessionStartTime is the value of SESSTARTTIME; the time the Evaluator was created is used when it is zero
+	SessionStartTime time.Time
+	// WorkflowStartTime is the value of WORKFLOWSTARTTIME; SessionStartTime is used when it is zero
+	WorkflowStartTime time.Time
+	// HighPrecision is the session's "Enable high precision" setting; when it is on decimals are computed exactly with
+	// up to 38 digits, otherwise they are converted to doubles and keep 15 significant digits
+	HighPrecision bool
+	// CodePage is the character set strings are converted to bytes with by functions such as MD5 and ENC_BASE64, one
+	// of UTF-8 (DefaultCodePage), ISO-8859-1, MS1252 or US-ASCII
+	CodePage string
+	// MappingVariables are the mapping variables of the session, which expressions can refer to and change with
+	// SETVARIABLE; they are shared by everything evaluated with the Evaluator
+	MappingVariables *MappingVariables
+}
+
+// Evaluator evaluates and compiles expressions with a set of Options
+// An Evaluator is never modified after it is created so it can be used from many goroutines at once
+type Evaluator struct {
+	options Options
+}
+
+// defaultEvaluator is used by the package level Evaluate and Compile; the default Options are always valid
+var defaultEvaluator, _ = NewEvaluator(Options{})
+
+// NewEvaluator returns an Evaluator with the options, filling in the defaults for any which aren't set
+// An unsupported CodePage is an error
+func NewEvaluator(options Options) (evaluator *Evaluator, err error) {
+	if options.Now == nil {
+		options.Now = time.Now
+	}
+	if options.SessionStartTime.IsZero() {
+		options.SessionStartTime = time.Now()
+	}
+	if options.WorkflowStartTime.IsZero() {
+		options.WorkflowStartTime = options.SessionStartTime
+	}
+
+	evaluator = &Evaluator{options: options}
+	if _, err = evaluator.codePage(); err != nil {
+		evaluator = nil
+	}
+
+	return
+}
+
+// Options returns the options of the evaluator, including the defaults that were filled in
+func (e *Evaluator) Options() Options {
+	return e.options
+}
+
+// precision converts a decimal to a double when high precision is disabled, as PowerCenter does
+func (e *Evaluator) precision(v Value) Value {
+	if e.options.HighPrecision || v.typ != TypeDecimal {
+		return v
+	}
+	if v.IsNull() {
+		return NewNull(TypeDouble)
+	}
+
+	return NewDouble(v.f)
+}
+
+// Evaluate will lex, parse, and finally evaluate the input and return the result
+func (e *Evaluator) Evaluate(input string, vars []Variable) (result Value, err error) {
+	node, errs, _ := parseAll([]byte(input), vars, e.declarations(nil))
+	if len(errs) > 0 {
+		err = errs[0]
+		return
+	}
+
+	result, err = evaluateNode(node, e.newEnvironment(nil))
+
+	return
+}
+
+// Compile lexes and parses the input once, resolving identifiers against the ports and parameters in the schema
+// The first problem found is returned as a *SyntaxError; use Validate to get all of them
+func (e *Evaluator) Compile(input string, schema []Declaration) (program *Program, err error) {
+	node, errs, _ := parseAll([]byte(input), nil, e.declarations(schema))
+	if len(errs) > 0 {
+		err = errs[0]
+		return
+	}
+
+	program = &Program{
+		input:     input,
+		node:      node,
+		schema:    append([]Declaration(nil), schema...),
+		evaluator: e,
+	}
+
+	return
+}
+
+// declarations returns the schema followed by the session's mapping variables
+func (e *Evaluator) declarations(schema []Declaration) []Declaration {
+	if e.options.MappingVariables == nil {
+		return schema
+	}
+
+	return append(append([]Declaration(nil), schema...), e.options.MappingVariables.Declarations()...)
+}
+
+// environment is what a single evaluation of an expression sees: the evaluator's options and the row's port values
+// A new environment is made for each evaluation so nothing is shared between goroutines
+// Aggregate functions see the rows of their group, or the groups of rows of a nested aggregate, and window functions
+// see the stream of rows and the rows which follow the current one
+type environment struct {
+	evaluator *Evaluator
+	row       Row
+	now       time.Time // the value of SYSDATE, read once so that every reference in the evaluation agrees
+	group     []Row
+	groups    [][]Row
+	stream    *Stream
+	following []Row
+}
+
+// newEnvironment returns the environment of one evaluation of a row, reading SYSDATE from the options
+func (e *Evaluator) newEnvironment(row Row) *environment {
+	return &environment{evaluator: e, row: row, now: e.options.Now()}
+}
@@ -0,0 +1,138 @@
+package expression
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newEvaluator returns an Evaluator with the options, failing the test if they aren't valid
+func newEvaluator(t *testing.T, options Options) *Evaluator {
+	t.Helper()
+	evaluator, err := NewEvaluator(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return evaluator
+}
+
+func TestEvaluatorOptions(t *testing.T) {
+	now := time.Date(2020, 3, 4, 5, 6, 7, 8000, time.UTC)
+	start := time.Date(2020, 3, 4, 1, 0, 0, 0, time.UTC)
+	evaluator := newEvaluator(t, Options{
+		Now:              func() time.Time { return now },
+		SessionStartTime: start,
+	})
+
+	testCases := []struct {
+		input  string
+		expect string
+	}{
+		{"SYSDATE", "03/04/2020 05:06:07.000008"},
+		{"SESSTARTTIME", "03/04/2020 01:00:00.000000"},
+		{"WORKFLOWSTARTTIME", "03/04/2020 01:00:00.000000"},
+		{"SYSDATE > SESSTARTTIME", "1"},
+	}
+
+	for _, tc := range testCases {
+		result, err := evaluator.Evaluate(tc.input, nil)
+		if err != nil {
+			t.Errorf("Input: %s\nUnexpected error: %v", tc.input, err)
+			continue
+		}
+		if result.String() != tc.expect {
+			t.Errorf("Input: %s\nExpected: `%s`, got `%s`", tc.input, tc.expect, result.String())
+		}
+	}
+}
+
+func TestSYSDATEReadOnce(t *testing.T) {
+	// the clock moves on every time it is read
+	now := time.Date(2020, 3, 4, 5, 6, 7, 0, time.UTC)
+	evaluator := newEvaluator(t, Options{Now: func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	}})
+
+	input := "DATE_DIFF(SYSDATE, SYSDATE, 'SS')"
+	result, err := evaluator.Evaluate(input, nil)
+	if err != nil || result.String() != "0" {
+		t.Errorf("Evaluate: Expected: `0`, got `%s` (%v)", result.String(), err)
+	}
+
+	program, err := evaluator.Compile(input, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result, err = program.Run(Row{}); err != nil || result.String() != "0" {
+		t.Errorf("Run: Expected: `0`, got `%s` (%v)", result.String(), err)
+	}
+	if result, err = program.Stream().Next(Row{}); err != nil || result.String() != "0" {
+		t.Errorf("Next: Expected: `0`, got `%s` (%v)", result.String(), err)
+	}
+}
+
+func TestEvaluatorDefaults(t *testing.T) {
+	options := newEvaluator(t, Options{}).Options()
+	if options.Now == nil || options.SessionStartTime.IsZero() {
+		t.Errorf("Expected the defaults to be filled in, got %+v", options)
+	}
+	if !options.WorkflowStartTime.Equal(options.SessionStartTime) {
+		t.Errorf("Expected: `%v`, got `%v`", options.SessionStartTime, options.WorkflowStartTime)
+	}
+}
+
+// TestConcurrency evaluates, compiles, runs and validates expressions from many goroutines at once
+// Run with -race to check that nothing is shared between them
+func TestConcurrency(t *testing.T) {
+	program, err := Compile("IIF(in_QTY > 1, CONCAT(in_NAME, in_QTY), DECODE(in_QTY, 0, 'zero', 'one'))", programSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 100)
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				n := int32(g*50 + i%3)
+				expect := map[int32]string{0: "zero", 1: "one"}[n]
+				if expect == "" {
+					expect = fmt.Sprintf("x%d", n)
+				}
+
+				result, err := program.Run(Row{"in_NAME": NewString("x"), "in_QTY": NewInteger(n)})
+				if err != nil || result.String() != expect {
+					errs <- fmt.Errorf("Run with %d: expected `%s`, got `%s` (%v)", n, expect, result, err)
+					return
+				}
+
+				input := fmt.Sprintf("ABS(-%d) + LTRIM(' 1')", n)
+				result, err = Evaluate(input, nil)
+				if err != nil || result.Int() != int64(n)+1 {
+					errs <- fmt.Errorf("Evaluate %s: expected `%d`, got `%s` (%v)", input, n+1, result, err)
+					return
+				}
+
+				if _, err = Compile(input, nil); err != nil {
+					errs <- err
+					return
+				}
+				if diagnostics := Validate("ABS(in_MISSING)", nil); len(diagnostics) != 1 {
+					errs <- fmt.Errorf("Validate: expected 1 diagnostic, got %v", diagnostics)
+					return
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Error(err)
+	}
+}
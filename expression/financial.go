@@ -0,0 +1,164 @@
+// financial functions
+// The formulas are the standard spreadsheet ones: a payment is made each period at its end, or at its start when type
+// is TRUE, and money paid out is negative while money received is positive
+
+package expression
+
+import (
+	"fmt"
+	"math"
+)
+
+// rateIterations and rateTolerance control the solver of RATE, which gives up like a spreadsheet does when 20 steps
+// of Newton's method from a guess of 10% don't get two successive rates within 0.0000001 of each other
+const (
+	rateGuess      = 0.1
+	rateIterations = 20
+	rateTolerance  = 1e-7
+)
+
+// financialArgs converts the arguments of a financial function to floats; the optional future or present value is 0
+// when omitted and type is 1 for payments at the start of each period or 0 for the end
+// null is true when any argument is NULL, making the result NULL
+func financialArgs(fn string, args []Value) (x [5]float64, null bool, err error) {
+	if anyNull(args) {
+		null = true
+		return
+	}
+
+	for i, arg := range args {
+		var n Value
+		if n, err = toNumeric(fn, arg); err != nil {
+			return
+		}
+		x[i] = n.Float()
+	}
+	if x[4] != 0 {
+		x[4] = 1
+	}
+
+	return
+}
+
+// annuity returns (1 + rate * type) * ((1 + rate)^terms - 1) / rate, the future value of a payment of 1 each period,
+// which is terms when rate is 0
+func annuity(rate, terms, typ float64) float64 {
+	if rate == 0 {
+		return terms
+	}
+
+	return (1 + rate*typ) * (math.Pow(1+rate, terms) - 1) / rate
+}
+
+// fv returns the future value of an investment with periodic payments and an optional present value
+func fv(args ...Value) (result Value, err error) {
+	x, null, err := financialArgs("FV", args)
+	if null || err != nil {
+		result = NewNull(TypeDouble)
+		return
+	}
+
+	rate, terms, payment, pv, typ := x[0], x[1], x[2], x[3], x[4]
+	result, err = doubleResult("FV", -(pv*math.Pow(1+rate, terms) + payment*annuity(rate, terms, typ)))
+
+	return
+}
+
+// pv returns the present value of a number of periodic payments and an optional future value
+func pv(args ...Value) (result Value, err error) {
+	x, null, err := financialArgs("PV", args)
+	if null || err != nil {
+		result = NewNull(TypeDouble)
+		return
+	}
+
+	rate, terms, payment, fv, typ := x[0], x[1], x[2], x[3], x[4]
+	result, err = doubleResult("PV", -(fv+payment*annuity(rate, terms, typ))/math.Pow(1+rate, terms))
+
+	return
+}
+
+// pmt returns the payment each period of a loan with a present value and an optional future value
+func pmt(args ...Value) (result Value, err error) {
+	x, null, err := financialArgs("PMT", args)
+	if null || err != nil {
+		result = NewNull(TypeDouble)
+		return
+	}
+
+	rate, terms, pv, fv, typ := x[0], x[1], x[2], x[3], x[4]
+	a := annuity(rate, terms, typ)
+	if a == 0 {
+		err = &RowError{Function: "PMT", Message: fmt.Sprintf("there is no payment over %s terms", formatDouble(terms))}
+		return
+	}
+	result, err = doubleResult("PMT", -(fv+pv*math.Pow(1+rate, terms))/a)
+
+	return
+}
+
+// nper returns the number of periods needed to pay off a present value, or reach a future value, with the payment
+func nper(args ...Value) (result Value, err error) {
+	x, null, err := financialArgs("NPER", args)
+	if null || err != nil {
+		result = NewNull(TypeDouble)
+		return
+	}
+
+	rate, pv, payment, fv, typ := x[0], x[1], x[2], x[3], x[4]
+	if rate == 0 {
+		if payment == 0 {
+			err = &RowError{Function: "NPER", Message: "the payment is 0 and the rate is 0"}
+			return
+		}
+		result, err = doubleResult("NPER", -(fv+pv)/payment)
+		return
+	}
+
+	p := payment * (1 + rate*typ)
+	ratio := (p - fv*rate) / (p + pv*rate)
+	if ratio <= 0 || rate <= -1 {
+		err = &RowError{Function: "NPER", Message: "the payment can never reach the future value"}
+		return
+	}
+	result, err = doubleResult("NPER", math.Log(ratio)/math.Log(1+rate))
+
+	return
+}
+
+// rate returns the interest rate per period which gives the present value from the payments and future value
+// It is solved for with Newton's method and a row error is returned if that doesn't converge
+func rate(args ...Value) (result Value, err error) {
+	x, null, err := financialArgs("RATE", args)
+	if null || err != nil {
+		result = NewNull(TypeDouble)
+		return
+	}
+
+	terms, payment, pv, fv, typ := x[0], x[1], x[2], x[3], x[4]
+	// f is the sum of the present value, payments and future value at the end, which is 0 at the rate
+	f := func(r float64) float64 {
+		return pv*math.Pow(1+r, terms) + payment*annuity(r, terms, typ) + fv
+	}
+
+	r := rateGuess
+	for i := 0; i < rateIterations; i++ {
+		// the derivative is estimated numerically, which is accurate enough for a step and avoids the special
+		// case at a rate of 0
+		h := 1e-7 * math.Max(1, math.Abs(r))
+		slope := (f(r+h) - f(r-h)) / (2 * h)
+		if slope == 0 || math.IsNaN(slope) || math.IsInf(slope, 0) {
+			break
+		}
+		next := r - f(r)/slope
+		if math.Abs(next-r) < rateTolerance {
+			result, err = doubleResult("RATE", next)
+			return
+		}
+		r = next
+	}
+
+	err = &RowError{Function: "RATE", Message: fmt.Sprintf("the rate didn't converge after %d iterations", rateIterations)}
+
+	return
+}
@@ -0,0 +1,33 @@
+package expression
+
+import (
+	"testing"
+)
+
+// The results are rounded as float noise makes the last digits differ from a spreadsheet's
+func TestFinancial(t *testing.T) {
+	runStringTests(t, []stringTestCase{
+		{`ROUND(FV(0.015, 12, -250, -2000, TRUE), 8)`, `5700.44374348`},
+		{`ROUND(FV(0.005, 60, -100), 8)`, `6977.00305099`},
+		{`FV(0, 12, -100)`, `1200`},
+		{`FV(NULL, 12, -100)`, `NULL`},
+		{`ROUND(PV(0.0075, 48, -500), 8)`, `20092.39094259`},
+		{`ROUND(PV(0.01, 12, 0, -1000), 8)`, `887.44922527`},
+		{`PV(0, 10, -100, -50)`, `1050`},
+		{`ROUND(PMT(0.01, 10, 20000), 8)`, `-2111.64153102`},
+		{`ROUND(PMT(0.0075, 48, 20000, 0, TRUE), 8)`, `-493.99587839`},
+		{`PMT(0, 10, 1000)`, `-100`},
+		{`ROUND(NPER(0.015, -2000, 500, 0, TRUE), 8)`, `4.09256074`},
+		{`ROUND(NPER(0.01, 20000, -500), 8)`, `51.33755162`},
+		{`NPER(0, 1000, -100)`, `10`},
+		{`NPER(0.01, 20000, NULL)`, `NULL`},
+		{`ROUND(RATE(48, -500, 20000), 8)`, `0.00770147`},
+		{`ROUND(RATE(12, -100, 1000, 0, TRUE), 8)`, `0.03503153`},
+		{`ROUND(RATE(10, 0, -1000, 2000), 8)`, `0.07177346`},
+		{`RATE(48, -500, NULL)`, `NULL`},
+		{`PMT(0.01, 0, 20000)`, `<<Expression Error>> [PMT]: there is no payment over 0 terms`},
+		{`NPER(0.01, 20000, -100)`, `<<Expression Error>> [NPER]: the payment can never reach the future value`},
+		{`NPER(0, 1000, 0)`, `<<Expression Error>> [NPER]: the payment is 0 and the rate is 0`},
+		{`RATE(48, 500, 20000)`, `<<Expression Error>> [RATE]: the rate didn't converge after 20 iterations`},
+	})
+}
@@ -1,192 +1,750 @@
 package expression
 
 import (
+	"bytes"
 	"fmt"
 	"math"
-	"reflect"
-	"strconv"
+	"math/big"
 	"strings"
 )
 
 // map the function name to the Go implementation to call
-var fns map[string]interface{}
+// The maps of functions are only written in init, so they can be read from many goroutines
+var fns map[string]func(args ...Value) (Value, error)
 
 func init() {
-	fns = map[string]interface{}{
+	fns = map[string]func(args ...Value) (Value, error){
+		// operators
+		"+":   add,
+		"-":   subtract,
+		"*":   multiply,
+		"/":   divide,
+		"%":   modulus,
+		"<":   lessThan,
+		"<=":  lessThanOrEqual,
+		">":   greaterThan,
+		">=":  greaterThanOrEqual,
+		"=":   equal,
+		"<>":  notEqual,
+		"!=":  notEqual,
+		"^=":  notEqual,
+		"NOT": not,
+		"AND": and,
+		"OR":  or,
+		"||":  concat,
+		// functions
 		"ABS":    abs,
 		"CHR":    chr,
 		"CONCAT": concat,
+		"ERROR":  raiseError,
+		"ISNULL": isNull,
+		"ABORT":  abort,
 		"LTRIM":  ltrim,
 		"RTRIM":  rtrim,
+		// string functions
+		"ASCII":      ascii,
+		"CHRCODE":    chrCode,
+		"INITCAP":    initCap,
+		"INSTR":      inStr,
+		"IS_SPACES":  isSpaces,
+		"LENGTH":     length,
+		"LOWER":      lower,
+		"LPAD":       lpad,
+		"REPLACECHR": replaceChr,
+		"REPLACESTR": replaceStr,
+		"REVERSE":    reverse,
+		"RPAD":       rpad,
+		"SUBSTR":     substr,
+		"UPPER":      upper,
+		// date functions
+		"ADD_TO_DATE":    addToDate,
+		"DATE_COMPARE":   dateCompare,
+		"DATE_DIFF":      dateDiff,
+		"GET_DATE_PART":  getDatePart,
+		"LAST_DAY":       lastDay,
+		"MAKE_DATE_TIME": makeDateTime,
+		"ROUND":          round,
+		"SET_DATE_PART":  setDatePart,
+		"TO_CHAR":        toChar,
+		"TRUNC":          trunc,
+		// numeric functions
+		"CEIL":  ceil,
+		"COS":   mathFunction("COS", math.Cos, nil),
+		"COSH":  mathFunction("COSH", math.Cosh, nil),
+		"EXP":   mathFunction("EXP", math.Exp, nil),
+		"FLOOR": floor,
+		"LN":    mathFunction("LN", math.Log, positive),
+		"LOG":   logarithm,
+		"MOD":   mod,
+		"POWER": power,
+		"SIGN":  sign,
+		"SIN":   mathFunction("SIN", math.Sin, nil),
+		"SINH":  mathFunction("SINH", math.Sinh, nil),
+		"SQRT":  mathFunction("SQRT", math.Sqrt, notNegative),
+		"TAN":   mathFunction("TAN", math.Tan, nil),
+		"TANH":  mathFunction("TANH", math.Tanh, nil),
+		// conversion functions
+		"IS_NUMBER":  isNumber,
+		"TO_BIGINT":  toBigint,
+		"TO_DECIMAL": toDecimal,
+		"TO_FLOAT":   toFloat,
+		"TO_INTEGER": toInteger,
+		// regular expression functions
+		"REG_EXTRACT": regExtract,
+		"REG_MATCH":   regMatch,
+		"REG_REPLACE": regReplace,
+		// encoding functions
+		"DEC_BASE64": decBase64,
+		// financial functions
+		"FV":   fv,
+		"NPER": nper,
+		"PMT":  pmt,
+		"PV":   pv,
+		"RATE": rate,
+		// binary functions
+		"BINARY_COMPARE": binaryCompare,
+		"BINARY_CONCAT":  binaryConcat,
+		"BINARY_LENGTH":  binaryLength,
+		"BINARY_SECTION": binarySection,
+	}
+
+	envFns = map[string]func(env *environment, args ...Value) (Value, error){
+		"AES_DECRYPT":  aesDecrypt,
+		"AES_ENCRYPT":  aesEncrypt,
+		"COMPRESS":     compress,
+		"CRC32":        crc32Checksum,
+		"DECOMPRESS":   decompress,
+		"DEC_HEX":      decHex,
+		"ENC_BASE64":   encBase64,
+		"ENC_HEX":      encHex,
+		"MD5":          hashFunction(md5Sum),
+		"SHA256":       hashFunction(sha256Sum),
+		"IS_DATE":      isDate,
+		"SYSTIMESTAMP": sysTimestamp,
+		"TO_DATE":      toDate,
+	}
+
+	lazyFns = map[string]func(env *environment, args ...Node) (Value, error){
+		"CHOOSE":  choose,
+		"DECODE":  decode,
+		"IIF":     iif,
+		"IN":      in,
+		"INDEXOF": indexOf,
+		// aggregate functions evaluate their arguments once for each row of the group
+		"AVG":        avg,
+		"COUNT":      count,
+		"FIRST":      first,
+		"LAST":       last,
+		"MAX":        maxAggregate,
+		"MEDIAN":     median,
+		"MIN":        minAggregate,
+		"PERCENTILE": percentile,
+		"STDDEV":     stddev,
+		"SUM":        sum,
+		"VARIANCE":   variance,
+		// window functions keep their state in the environment's stream
+		"CUME":      cume,
+		"LAG":       lag,
+		"LEAD":      lead,
+		"MOVINGAVG": movingAvg,
+		"MOVINGSUM": movingSum,
+		// the first argument of the SETVARIABLE functions is the mapping variable rather than its value
+		"SETCOUNTVARIABLE": setCountVariable,
+		"SETMAXVARIABLE":   setMaxVariable,
+		"SETMINVARIABLE":   setMinVariable,
+		"SETVARIABLE":      setVariable,
 	}
 }
 
+// map the function name to a Go implementation which depends on the Evaluator's options, e.g. the current date
+var envFns map[string]func(env *environment, args ...Value) (Value, error)
+
+// map the function name to a Go implementation which evaluates its own arguments
+// This allows short-circuiting so that only the arguments which are needed get evaluated, and aggregate functions to
+// evaluate them for each row
+var lazyFns map[string]func(env *environment, args ...Node) (Value, error)
+
 // Evaluate will lex, parse, and finally evaluate the input and return the result
-func Evaluate(input string, vars []Variable) (result string, err error) {
-	node, err := parse([]byte(input), vars)
-	if err != nil {
+// It uses an Evaluator with the default Options
+func Evaluate(input string, vars []Variable) (result Value, err error) {
+	return defaultEvaluator.Evaluate(input, vars)
+}
+
+// evaluateNode evaluates the node with the values of the ports in the environment's row
+// The node isn't modified, so the same AST can be evaluated from many goroutines
+func evaluateNode(node Node, env *environment) (result Value, err error) {
+	switch node.Exp {
+	case "VALUE":
+		// Values are already evaluated
+		result = env.evaluator.precision(node.Value)
+		return
+	case "IDENT":
+		// Ports are bound to the row, a port missing from the row is NULL
+		// Mapping variables have their start value for the whole session, SETVARIABLE only changes the current value
+		if m := env.evaluator.options.MappingVariables; m != nil && m.declared(node.Name) {
+			result = m.Start(node.Name)
+		} else if v, ok := env.row[node.Name]; ok && v.typ != TypeNull {
+			result = v
+		} else {
+			result = node.Value
+		}
+		result = env.evaluator.precision(result)
+		return
+	case "SYSDATE":
+		result = NewDate(env.now)
+		return
+	case "SESSTARTTIME":
+		result = NewDate(env.evaluator.options.SessionStartTime)
+		return
+	case "WORKFLOWSTARTTIME":
+		result = NewDate(env.evaluator.options.WorkflowStartTime)
 		return
 	}
 
-	node, err = evaluateNode(node)
-	if err != nil {
+	// Lazy functions get the unevaluated args
+	if function, ok := lazyFns[node.Exp]; ok {
+		result, err = function(env, node.Args...)
 		return
 	}
 
-	result = node.Args[0].(string)
-
-	return
-}
-
-func evaluateNode(node Node) (result Node, err error) {
 	// Get the function from the node
-	if _, ok := fns[node.Exp]; !ok {
+	function, ok := fns[node.Exp]
+	envFunction, envOk := envFns[node.Exp]
+	if !ok && !envOk {
 		err = fmt.Errorf("the function %s either is invalid or hasn't been implemented by this library", node.Exp)
 		return
 	}
-	function := reflect.ValueOf(fns[node.Exp])
 
-	// If any arg is a node, evaluate it
+	// Evaluate the args before calling the function with their values
+	args := make([]Value, len(node.Args))
 	for i, arg := range node.Args {
-		if n, ok := arg.(Node); !ok {
-			err = fmt.Errorf("expected Node but got '%s'\n%+v", reflect.TypeOf(n), n)
+		args[i], err = evaluateNode(arg, env)
+		if err != nil {
 			return
-		} else if ok {
-			if n.Exp != "NUMBER" && n.Exp != "STRING" && n.Exp != "NULL" {
-				node.Args[i], err = evaluateNode(arg.(Node))
-				if err != nil {
-					return
-				}
-			}
 		}
 	}
 
-	// Convert the args to Value for usage with the reflected function
-	in := make([]reflect.Value, len(node.Args))
-	for k, arg := range node.Args {
-		in[k] = reflect.ValueOf(arg)
+	if envOk {
+		result, err = envFunction(env, args...)
+	} else {
+		result, err = function(args...)
 	}
+	result = env.evaluator.precision(result)
 
-	// Call the function
-	value := function.Call(in)
-	if !value[1].IsNil() {
-		err = value[1].Interface().(error)
-		return
+	return
+}
+
+// toNumeric returns a numeric value as is and converts a string holding a number (e.g. '5') to a number
+// PowerCenter converts numeric strings implicitly but rejects anything else
+func toNumeric(fn string, v Value) (result Value, err error) {
+	switch {
+	case v.typ.IsNumeric():
+		result = v
+	case v.typ == TypeString:
+		result, err = parseNumber(v.s)
+		if err != nil {
+			err = fmt.Errorf("%s cannot convert '%s' to a number", fn, v.s)
+		}
+	default:
+		err = fmt.Errorf("%s expected a number but got %s", fn, v.typ)
 	}
-	result = value[0].Interface().(Node)
 
 	return
 }
 
-func abs(args ...Node) (result Node, err error) {
-	if len(args) != 1 {
-		err = fmt.Errorf("incorrect number of arguments, %d, to ABS", len(args))
+// toText renders any value as a string for functions which accept any datatype
+func toText(v Value) string {
+	if v.typ == TypeString {
+		return v.s
+	}
+
+	return v.String()
+}
+
+func abs(args ...Value) (result Value, err error) {
+	if args[0].IsNull() {
+		result = NewNull(args[0].typ)
 		return
 	}
 
-	if args[0].Exp == "NULL" {
-		result.Exp = "NULL"
-		result.Args = append(result.Args, "NULL")
+	result, err = toNumeric("ABS", args[0])
+	if err != nil {
 		return
 	}
 
-	num, err := strconv.ParseFloat(args[0].Args[0].(string), 64)
+	switch result.typ {
+	case TypeInteger, TypeBigint:
+		if result.i < 0 {
+			result.i = -result.i
+		}
+	case TypeDecimal:
+		result.f = math.Abs(result.f)
+		result.d = new(big.Int).Abs(result.d)
+	default:
+		result.f = math.Abs(result.f)
+	}
+
+	return
+}
+
+func chr(args ...Value) (result Value, err error) {
+	if args[0].IsNull() {
+		result = NewNull(TypeString)
+		return
+	}
+
+	n, err := toNumeric("CHR", args[0])
 	if err != nil {
 		return
 	}
 
-	num = math.Abs(num)
+	result = NewString(string(rune(n.Int())))
+
+	return
+}
 
-	result.Exp = "NUMBER"
-	result.Args = append(result.Args, fmt.Sprintf("%f", num))
+// concat joins two values as strings, for CONCAT and the || operator
+// A NULL is treated as an empty string, the result is only NULL when both values are NULL
+func concat(args ...Value) (result Value, err error) {
+	switch {
+	case args[0].IsNull() && args[1].IsNull():
+		result = NewNull(TypeString)
+	case args[0].IsNull():
+		result = NewString(toText(args[1]))
+	case args[1].IsNull():
+		result = NewString(toText(args[0]))
+	default:
+		result = NewString(toText(args[0]) + toText(args[1]))
+	}
 
 	return
 }
 
-func chr(args ...Node) (result Node, err error) {
-	if len(args) != 1 {
-		err = fmt.Errorf("incorrect number of arguments, %d, to CHR", len(args))
-		return
+// isNull returns TRUE (1) if the value is NULL, otherwise FALSE (0)
+func isNull(args ...Value) (result Value, err error) {
+	result = boolValue(args[0].IsNull())
+
+	return
+}
+
+// ltrim removes blanks, or the characters in the trim set, from the start of the string
+func ltrim(args ...Value) (result Value, err error) {
+	return trim(args, strings.TrimLeft)
+}
+
+// rtrim removes blanks, or the characters in the trim set, from the end of the string
+func rtrim(args ...Value) (result Value, err error) {
+	return trim(args, strings.TrimRight)
+}
+
+// trim implements LTRIM and RTRIM; the trim set defaults to a single blank
+func trim(args []Value, fn func(s string, cutset string) string) (result Value, err error) {
+	cutset := ` `
+	if len(args) > 1 {
+		if args[1].IsNull() {
+			result = NewNull(TypeString)
+			return
+		}
+		cutset = toText(args[1])
 	}
 
-	if args[0].Exp == "NULL" {
-		result.Exp = "NULL"
-		result.Args = append(result.Args, "NULL")
+	if args[0].IsNull() {
+		result = NewNull(TypeString)
 		return
 	}
 
-	result.Exp = "STRING"
+	result = NewString(fn(toText(args[0]), cutset))
 
-	f, err := strconv.ParseFloat(args[0].Args[0].(string), 64)
-	if err != nil {
+	return
+}
+
+// add returns the sum of two numbers, or NULL if either is NULL
+func add(args ...Value) (result Value, err error) {
+	return arithmetic("+", args, func(a, b int64) (int64, bool, error) {
+		r := a + b
+		return r, (a > 0 && b > 0 && r < 0) || (a < 0 && b < 0 && r >= 0), nil
+	}, func(a, b float64) (float64, error) {
+		return a + b, nil
+	})
+}
+
+// subtract returns the difference of two numbers, or NULL if either is NULL
+// With a single argument it is unary minus and returns the negated number
+func subtract(args ...Value) (result Value, err error) {
+	if len(args) == 1 {
+		if args[0].IsNull() {
+			result = NewNull(args[0].typ)
+			return
+		}
+		n, nErr := toNumeric("operator -", args[0])
+		if nErr != nil {
+			err = nErr
+			return
+		}
+		result, err = negate(n)
 		return
 	}
-	ascii := int(f)
 
-	result.Args = append(result.Args, string(rune(ascii)))
+	return arithmetic("-", args, func(a, b int64) (int64, bool, error) {
+		r := a - b
+		return r, (a >= 0 && b < 0 && r < 0) || (a < 0 && b > 0 && r >= 0), nil
+	}, func(a, b float64) (float64, error) {
+		return a - b, nil
+	})
+}
+
+// multiply returns the product of two numbers, or NULL if either is NULL
+func multiply(args ...Value) (result Value, err error) {
+	return arithmetic("*", args, func(a, b int64) (int64, bool, error) {
+		r := a * b
+		return r, a != 0 && (r/a != b || (a == -1 && b == math.MinInt64)), nil
+	}, func(a, b float64) (float64, error) {
+		return a * b, nil
+	})
+}
+
+// negate returns the number with its sign flipped
+// Negating the smallest integer doesn't fit in an integer, so it becomes a bigint
+func negate(v Value) (result Value, err error) {
+	switch v.typ {
+	case TypeInteger:
+		result = NewBigint(-v.i)
+		if v.i != math.MinInt32 {
+			result = NewInteger(int32(-v.i))
+		}
+	case TypeBigint:
+		if v.i == math.MinInt64 {
+			result = NewDouble(-float64(v.i))
+		} else {
+			result = NewBigint(-v.i)
+		}
+	case TypeDecimal:
+		result = v
+		result.f = -v.f
+		result.d = new(big.Int).Neg(v.d)
+	case TypeDouble:
+		result = v
+		result.f = -v.f
+	default:
+		err = fmt.Errorf("operator - expected a number but got %s", v.typ)
+	}
+
 	return
 }
 
-func concat(args ...Node) (result Node, err error) {
+// divide returns the quotient of two numbers, or NULL if either is NULL
+// The quotient is always a double, even for integers (e.g. 7 / 2 = 3.5)
+// Dividing by zero is a row error in PowerCenter, so it is returned as a RowError
+func divide(args ...Value) (result Value, err error) {
+	return arithmetic("/", args, nil, func(a, b float64) (float64, error) {
+		if b == 0 {
+			return 0, &RowError{Function: "/", Message: "divide by zero"}
+		}
+		return a / b, nil
+	})
+}
+
+// modulus returns the remainder of a division, or NULL if either is NULL
+// Decimals are allowed (e.g. 5.5 % 2 = 1.5) and the sign follows the dividend
+func modulus(args ...Value) (result Value, err error) {
+	return arithmetic("%", args, func(a, b int64) (int64, bool, error) {
+		if b == 0 {
+			return 0, false, &RowError{Function: "%", Message: "divide by zero"}
+		}
+		if b == -1 {
+			return 0, false, nil
+		}
+		return a % b, false, nil
+	}, func(a, b float64) (float64, error) {
+		if b == 0 {
+			return 0, &RowError{Function: "%", Message: "divide by zero"}
+		}
+		return math.Mod(a, b), nil
+	})
+}
+
+// arithmetic applies a binary numeric operator to the args after checking for NULLs and converting them to numbers
+// intFn is used when both operands are integers and reports if the result overflowed, in which case floatFn is used
+// The result has the widest type of the operands: integer, then bigint, then decimal, then double
+// Decimals are computed exactly by decimalArithmetic
+func arithmetic(op string, args []Value, intFn func(a, b int64) (int64, bool, error),
+	floatFn func(a, b float64) (float64, error)) (result Value, err error) {
 	if len(args) != 2 {
-		err = fmt.Errorf("incorrect number of arguments, %d, to CONCAT", len(args))
+		err = fmt.Errorf("incorrect number of arguments, %d, to %s", len(args), op)
 		return
 	}
 
-	result.Exp = "STRING"
+	// any NULL operand makes the whole operation NULL
+	if args[0].IsNull() || args[1].IsNull() {
+		t := widestType(args[0].typ, args[1].typ)
+		if intFn == nil && t != TypeNull {
+			t = TypeDouble
+		}
+		result = NewNull(t)
+		return
+	}
+
+	a, err := toNumeric("operator "+op, args[0])
+	if err != nil {
+		return
+	}
+	b, err := toNumeric("operator "+op, args[1])
+	if err != nil {
+		return
+	}
 
-	if args[0].Exp == "NULL" {
-		result.Args = append(result.Args, args[1].Args[0].(string))
+	t := widestType(a.typ, b.typ)
+
+	// decimals are only seen with high precision enabled, otherwise they have already become doubles
+	if t == TypeDecimal {
+		result, err = decimalArithmetic(op, a, b)
 		return
 	}
-	if args[1].Exp == "NULL" {
-		result.Args = append(result.Args, args[0].Args[0].(string))
+
+	if intFn != nil && (t == TypeInteger || t == TypeBigint) {
+		i, overflow, iErr := intFn(a.i, b.i)
+		if iErr != nil {
+			err = iErr
+			return
+		}
+		if !overflow {
+			if t == TypeInteger && i >= math.MinInt32 && i <= math.MaxInt32 {
+				result = NewInteger(int32(i))
+			} else {
+				result = NewBigint(i)
+			}
+			return
+		}
+		t = TypeDouble
+	}
+
+	f, err := floatFn(a.Float(), b.Float())
+	if err != nil {
 		return
 	}
 
-	result.Args = append(result.Args, args[0].Args[0].(string)+args[1].Args[0].(string))
+	result = NewDouble(f)
+
 	return
 }
 
-func ltrim(args ...Node) (result Node, err error) {
-	result.Exp = "STRING"
+// widestType returns the numeric type which can hold both types; NULL is ignored
+func widestType(a Type, b Type) Type {
+	order := map[Type]int{TypeNull: 0, TypeInteger: 1, TypeBigint: 2, TypeDecimal: 3, TypeDouble: 4}
+	oa, okA := order[a]
+	ob, okB := order[b]
+	switch {
+	case !okA:
+		return a
+	case !okB:
+		return b
+	case oa > ob:
+		return a
+	}
 
-	switch len(args) {
-	case 1:
-		v := strings.TrimLeft(args[0].Args[0].(string), ` `)
-		result.Args = append(result.Args, v)
-	case 2:
-		if args[0].Exp == "NULL" || args[1].Exp == "NULL" {
-			result.Exp = "NULL"
-			result.Args = append(result.Args, "NULL")
-		} else {
-			v := strings.TrimLeft(args[0].Args[0].(string), args[1].Args[0].(string))
-			result.Args = append(result.Args, v)
+	return b
+}
+
+// decimalScale returns the scale of a decimal, or 0 for integers
+func (v Value) decimalScale() int {
+	if v.typ == TypeDecimal {
+		return v.scale
+	}
+
+	return 0
+}
+
+func lessThan(args ...Value) (result Value, err error) {
+	return comparison("<", args, func(c int) bool { return c < 0 })
+}
+
+func lessThanOrEqual(args ...Value) (result Value, err error) {
+	return comparison("<=", args, func(c int) bool { return c <= 0 })
+}
+
+func greaterThan(args ...Value) (result Value, err error) {
+	return comparison(">", args, func(c int) bool { return c > 0 })
+}
+
+func greaterThanOrEqual(args ...Value) (result Value, err error) {
+	return comparison(">=", args, func(c int) bool { return c >= 0 })
+}
+
+func equal(args ...Value) (result Value, err error) {
+	return comparison("=", args, func(c int) bool { return c == 0 })
+}
+
+func notEqual(args ...Value) (result Value, err error) {
+	return comparison("<>", args, func(c int) bool { return c != 0 })
+}
+
+// comparison compares two values of the same type and returns TRUE (1) or FALSE (0), or NULL if either is NULL
+func comparison(op string, args []Value, fn func(c int) bool) (result Value, err error) {
+	if len(args) != 2 {
+		err = fmt.Errorf("incorrect number of arguments, %d, to %s", len(args), op)
+		return
+	}
+
+	if args[0].IsNull() || args[1].IsNull() {
+		result = NewNull(TypeInteger)
+		return
+	}
+
+	c, err := compare("operator "+op, args[0], args[1])
+	if err != nil {
+		return
+	}
+
+	result = boolValue(fn(c))
+
+	return
+}
+
+// compare returns -1, 0, or 1 when a is less than, equal to, or greater than b
+// Numbers are compared numerically, strings and binaries by their bytes, and dates chronologically
+func compare(fn string, a Value, b Value) (c int, err error) {
+	switch {
+	case a.typ.IsNumeric() && b.typ.IsNumeric():
+		if a.typ != TypeDecimal && a.typ != TypeDouble && b.typ != TypeDecimal && b.typ != TypeDouble {
+			c = compareInts(a.i, b.i)
+			return
+		}
+		if a.typ != TypeDouble && b.typ != TypeDouble {
+			c = a.rat().Cmp(b.rat())
+			return
+		}
+		x, y := a.Float(), b.Float()
+		switch {
+		case x < y:
+			c = -1
+		case x > y:
+			c = 1
+		}
+	case a.typ != b.typ:
+		err = fmt.Errorf("%s cannot compare %s with %s", fn, a.typ, b.typ)
+	case a.typ == TypeString:
+		c = strings.Compare(a.s, b.s)
+	case a.typ == TypeDate:
+		switch {
+		case a.t.Before(b.t):
+			c = -1
+		case a.t.After(b.t):
+			c = 1
 		}
+	case a.typ == TypeBinary:
+		c = bytes.Compare(a.b, b.b)
 	default:
-		err = fmt.Errorf("incorrect number of arguments, %d, to LTRIM", len(args))
+		err = fmt.Errorf("%s cannot compare values of type %s", fn, a.typ)
 	}
 
 	return
 }
 
-func rtrim(args ...Node) (result Node, err error) {
-	result.Exp = "STRING"
+func compareInts(a int64, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
 
-	switch len(args) {
-	case 1:
-		v := strings.TrimRight(args[0].Args[0].(string), ` `)
-		result.Args = append(result.Args, v)
-	case 2:
-		if args[0].Exp == "NULL" || args[1].Exp == "NULL" {
-			result.Exp = "NULL"
-			result.Args = append(result.Args, "NULL")
-		} else {
-			v := strings.TrimRight(args[0].Args[0].(string), args[1].Args[0].(string))
-			result.Args = append(result.Args, v)
+	return 0
+}
+
+// not returns TRUE (1) for a FALSE condition and FALSE (0) for a TRUE condition, or NULL if the condition is NULL
+func not(args ...Value) (result Value, err error) {
+	if len(args) != 1 {
+		err = fmt.Errorf("incorrect number of arguments, %d, to NOT", len(args))
+		return
+	}
+
+	if args[0].IsNull() {
+		result = NewNull(TypeInteger)
+		return
+	}
+
+	b, err := isTrue("operator NOT", args[0])
+	if err != nil {
+		return
+	}
+	result = boolValue(!b)
+
+	return
+}
+
+// and implements three-valued logic: FALSE wins over NULL, otherwise NULL wins over TRUE
+func and(args ...Value) (result Value, err error) {
+	return logical("AND", args, false)
+}
+
+// or implements three-valued logic: TRUE wins over NULL, otherwise NULL wins over FALSE
+func or(args ...Value) (result Value, err error) {
+	return logical("OR", args, true)
+}
+
+// logical combines two conditions; dominant is the value that decides the result regardless of the other operand
+func logical(op string, args []Value, dominant bool) (result Value, err error) {
+	if len(args) != 2 {
+		err = fmt.Errorf("incorrect number of arguments, %d, to %s", len(args), op)
+		return
+	}
+
+	isNull := false
+	for _, arg := range args {
+		if arg.IsNull() {
+			isNull = true
+			continue
+		}
+		b, bErr := isTrue("operator "+op, arg)
+		if bErr != nil {
+			err = bErr
+			return
+		}
+		if b == dominant {
+			result = boolValue(dominant)
+			return
 		}
-	default:
-		err = fmt.Errorf("incorrect number of arguments, %d, to LTRIM", len(args))
 	}
+
+	if isNull {
+		result = NewNull(TypeInteger)
+	} else {
+		result = boolValue(!dominant)
+	}
+
+	return
+}
+
+// isTrue checks a non-NULL condition; any non-zero number is TRUE
+func isTrue(fn string, v Value) (b bool, err error) {
+	n, err := toNumeric(fn, v)
+	if err != nil {
+		return
+	}
+	b = n.Float() != 0
+
+	return
+}
+
+// boolValue returns the Informatica representation of a boolean, TRUE is 1 and FALSE is 0
+func boolValue(b bool) Value {
+	if b {
+		return NewInteger(1)
+	}
+
+	return NewInteger(0)
+}
+
+// raiseError implements ERROR, which skips the row with the given message
+func raiseError(args ...Value) (result Value, err error) {
+	err = &RowError{Function: "ERROR", Message: toText(args[0])}
+
+	return
+}
+
+// abort implements ABORT, which stops the session with the given message
+func abort(args ...Value) (result Value, err error) {
+	err = &AbortError{Message: toText(args[0])}
+
 	return
 }
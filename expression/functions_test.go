@@ -8,10 +8,10 @@ func TestABS(t *testing.T) {
 		expect string
 	}{
 		{`ABS(NULL)`, `NULL`},
-		{`ABS(250)`, `250.000000`},
-		{`ABS(-250)`, `250.000000`},
-		{`ABS(1.1)`, `1.100000`},
-		{`ABS(-1.1)`, `1.100000`},
+		{`ABS(250)`, `250`},
+		{`ABS(-250)`, `250`},
+		{`ABS(1.1)`, `1.1`},
+		{`ABS(-1.1)`, `1.1`},
 	}
 
 	vars := make([]Variable, 0)
@@ -22,8 +22,8 @@ func TestABS(t *testing.T) {
 			t.Error(err)
 		}
 
-		if result != tc.expect {
-			t.Errorf("Input: %s\nExpected: `%s`, got `%s`", tc.input, tc.expect, result)
+		if result.String() != tc.expect {
+			t.Errorf("Input: %s\nExpected: `%s`, got `%s`", tc.input, tc.expect, result.String())
 		}
 	}
 }
@@ -46,8 +46,8 @@ func TestCHR(t *testing.T) {
 			t.Error(err)
 		}
 
-		if result != tc.expect {
-			t.Errorf("Input: %s\nExpected: `%s`, got `%s`", tc.input, tc.expect, result)
+		if result.String() != tc.expect {
+			t.Errorf("Input: %s\nExpected: `%s`, got `%s`", tc.input, tc.expect, result.String())
 		}
 	}
 }
@@ -70,8 +70,8 @@ func TestCONCAT(t *testing.T) {
 			t.Error(err)
 		}
 
-		if result != tc.expect {
-			t.Errorf("Input: %s\nExpected: `%s`, got `%s`", tc.input, tc.expect, result)
+		if result.String() != tc.expect {
+			t.Errorf("Input: %s\nExpected: `%s`, got `%s`", tc.input, tc.expect, result.String())
 		}
 	}
 }
@@ -83,7 +83,7 @@ func TestLTRIM(t *testing.T) {
 	}{
 		{`LTRIM('H. Bender', 'S.')`, `H. Bender`},
 		{`LTRIM(NULL)`, `NULL`},
-		{`LTRIM(RTRIM(' a ')`, `a`},
+		{`LTRIM(RTRIM(' a '))`, `a`},
 	}
 
 	vars := make([]Variable, 0)
@@ -94,8 +94,8 @@ func TestLTRIM(t *testing.T) {
 			t.Error(err)
 		}
 
-		if result != tc.expect {
-			t.Errorf("Input: %s\nExpected: `%s`, got `%s`", tc.input, tc.expect, result)
+		if result.String() != tc.expect {
+			t.Errorf("Input: %s\nExpected: `%s`, got `%s`", tc.input, tc.expect, result.String())
 		}
 	}
 }
@@ -117,8 +117,211 @@ func TestRTRIM(t *testing.T) {
 			t.Error(err)
 		}
 
-		if result != tc.expect {
-			t.Errorf("Input: %s\nExpected: `%s`, got `%s`", tc.input, tc.expect, result)
+		if result.String() != tc.expect {
+			t.Errorf("Input: %s\nExpected: `%s`, got `%s`", tc.input, tc.expect, result.String())
+		}
+	}
+}
+
+func TestArithmetic(t *testing.T) {
+	testCases := []struct {
+		input  string
+		expect string
+	}{
+		{`1 + 2`, `3`},
+		{`5 - 3`, `2`},
+		{`2 * 3.5`, `7`},
+		{`7 / 2`, `3.5`},
+		{`7 % 2`, `1`},
+		{`5.5 % 2`, `1.5`},
+		{`-5.5 % 2`, `-1.5`},
+		{`1 + 2 * 3`, `7`},
+		{`8 + (5 - 2) * 8`, `32`},
+		{`10 - 4 - 3`, `3`},
+		{`1 + NULL`, `NULL`},
+		{`NULL * 2`, `NULL`},
+		{`NULL / 0`, `NULL`},
+		{`'5' + 1`, `6`},
+		{`ABS(-2) * 3`, `6`},
+		{`5-3`, `2`},
+		{`1 - -2`, `3`},
+		{`-ABS(-3)`, `-3`},
+		{`- (1 + 2) * 2`, `-6`},
+		{`-2147483648 - 1`, `-2147483649`},
+		{`-NULL`, `NULL`},
+	}
+
+	vars := make([]Variable, 0)
+
+	for _, tc := range testCases {
+		result, err := Evaluate(tc.input, vars)
+		if err != nil {
+			t.Error(err)
+		}
+
+		if result.String() != tc.expect {
+			t.Errorf("Input: %s\nExpected: `%s`, got `%s`", tc.input, tc.expect, result.String())
+		}
+	}
+}
+
+func TestArithmeticErrors(t *testing.T) {
+	testCases := []struct {
+		input    string
+		rowError bool
+	}{
+		{`1 / 0`, true},
+		{`1 % 0`, true},
+		{`'abc' + 1`, false},
+		{`1 * 'x1'`, false},
+	}
+
+	vars := make([]Variable, 0)
+
+	for _, tc := range testCases {
+		_, err := Evaluate(tc.input, vars)
+		if err == nil {
+			t.Errorf("Input: %s\nExpected an error", tc.input)
+			continue
+		}
+
+		if _, ok := err.(*RowError); ok != tc.rowError {
+			t.Errorf("Input: %s\nExpected RowError %v, got %T: %v", tc.input, tc.rowError, err, err)
+		}
+	}
+}
+
+func TestComparison(t *testing.T) {
+	testCases := []struct {
+		input  string
+		expect string
+	}{
+		{`1 < 2`, `1`},
+		{`2 < 1`, `0`},
+		{`2 <= 2`, `1`},
+		{`3 > 2.5`, `1`},
+		{`2 >= 3`, `0`},
+		{`2 = 2.0`, `1`},
+		{`2 <> 2`, `0`},
+		{`2 != 3`, `1`},
+		{`2 ^= 3`, `1`},
+		{`'abc' = 'abc'`, `1`},
+		{`'abc' < 'abd'`, `1`},
+		{`'B' > 'a'`, `0`},
+		{`1 + 1 = 2`, `1`},
+		{`NULL = NULL`, `NULL`},
+		{`1 < NULL`, `NULL`},
+		{`TRUE = 1`, `1`},
+		{`FALSE`, `0`},
+		{`d1 < d2`, `1`},
+		{`d1 = d1`, `1`},
+	}
+
+	vars := []Variable{
+		{"d1", "DATE", "01/31/2020 23:59:59"},
+		{"d2", "DATE", "02/01/2020 00:00:00.5"},
+	}
+
+	for _, tc := range testCases {
+		result, err := Evaluate(tc.input, vars)
+		if err != nil {
+			t.Error(err)
+		}
+
+		if result.String() != tc.expect {
+			t.Errorf("Input: %s\nExpected: `%s`, got `%s`", tc.input, tc.expect, result.String())
+		}
+	}
+}
+
+func TestComparisonErrors(t *testing.T) {
+	testCases := []string{
+		`1 < 'a'`,
+		`'a' = d1`,
+	}
+
+	vars := []Variable{
+		{"d1", "DATE", "01/31/2020 23:59:59"},
+	}
+
+	for _, tc := range testCases {
+		if _, err := Evaluate(tc, vars); err == nil {
+			t.Errorf("Input: %s\nExpected an error", tc)
+		}
+	}
+}
+
+func TestLogical(t *testing.T) {
+	testCases := []struct {
+		input  string
+		expect string
+	}{
+		{`TRUE AND TRUE`, `1`},
+		{`TRUE AND FALSE`, `0`},
+		{`FALSE OR TRUE`, `1`},
+		{`FALSE OR FALSE`, `0`},
+		{`NULL AND TRUE`, `NULL`},
+		{`NULL AND FALSE`, `0`},
+		{`FALSE AND NULL`, `0`},
+		{`NULL OR TRUE`, `1`},
+		{`NULL OR FALSE`, `NULL`},
+		{`NULL OR NULL`, `NULL`},
+		{`5 AND 0.5`, `1`},
+		{`1 < 2 AND 3 < 4`, `1`},
+		{`1 > 2 OR 3 < 4 AND 4 < 3`, `0`},
+		{`1 < 2 OR 3 < 4 AND 4 < 3`, `1`},
+		{`NOT TRUE`, `0`},
+		{`NOT 0`, `1`},
+		{`NOT NULL`, `NULL`},
+		{`NOT ISNULL(NULL)`, `0`},
+		{`NOT NOT 5`, `1`},
+		{`NOT 1 < 2 AND 3 < 4`, `1`},
+		{`NOT 1 = 2`, `0`},
+		{`NOT (1 = 2)`, `1`},
+		{`NOT 2 > 1 OR 1 = 1`, `1`},
+		{`- NOT 1`, `0`},
+	}
+
+	vars := make([]Variable, 0)
+
+	for _, tc := range testCases {
+		result, err := Evaluate(tc.input, vars)
+		if err != nil {
+			t.Error(err)
+		}
+
+		if result.String() != tc.expect {
+			t.Errorf("Input: %s\nExpected: `%s`, got `%s`", tc.input, tc.expect, result.String())
+		}
+	}
+}
+
+func TestConcatenation(t *testing.T) {
+	testCases := []struct {
+		input  string
+		expect string
+	}{
+		{`'a' || 'b'`, `ab`},
+		{`'a' || NULL`, `a`},
+		{`NULL || 'b'`, `b`},
+		{`NULL || NULL`, `NULL`},
+		{`'a' || 'b' || 'c'`, `abc`},
+		{`'a' || 1 + 2`, `a3`},
+		{`'a' || 'b' = 'ab'`, `1`},
+		{`1.5 || TO_DATE('2020-03-15', 'YYYY-MM-DD')`, `1.503/15/2020 00:00:00.000000`},
+	}
+
+	vars := make([]Variable, 0)
+
+	for _, tc := range testCases {
+		result, err := Evaluate(tc.input, vars)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+
+		if result.String() != tc.expect {
+			t.Errorf("Input: %s\nExpected: `%s`, got `%s`", tc.input, tc.expect, result.String())
 		}
 	}
 }
@@ -0,0 +1,32 @@
+package expression
+
+import (
+	"testing"
+)
+
+// stringTestCase is an expression and the String() of its result, or the message of the *RowError it fails with
+type stringTestCase struct {
+	input  string
+	expect string
+}
+
+// runStringTests evaluates each expression without any variables and compares the String() of its result, or the
+// message of its row error, with the expected one
+func runStringTests(t *testing.T, testCases []stringTestCase) {
+	t.Helper()
+
+	for _, tc := range testCases {
+		result, err := Evaluate(tc.input, nil)
+		got := result.String()
+		if rErr, ok := err.(*RowError); ok {
+			got = rErr.Error()
+		} else if err != nil {
+			t.Errorf("Input: %s\nUnexpected error: %v", tc.input, err)
+			continue
+		}
+
+		if got != tc.expect {
+			t.Errorf("Input: %s\nExpected: `%s`, got `%s`", tc.input, tc.expect, got)
+		}
+	}
+}
@@ -10,31 +10,58 @@ import (
 	"github.com/timtadh/lexmachine/machines"
 )
 
-// Literals are tokens representing literal strings
-var Literals []string
+// The token tables and lexer are built once in init and only read afterwards, so scanning is safe from many goroutines
 
-// Keywords tokens
-var Keywords []string
+// literals are tokens representing literal strings
+var literals []string
 
-// Tokens including both Literals and Keywords
-var Tokens []string
+// keywords tokens
+var keywords []string
 
-// TokenIds map token name -> int id
-var TokenIds map[string]int
+// tokenNames including both literals and keywords
+var tokenNames []string
 
-// Lexer object to create a scanner
-var Lexer *lexmachine.Lexer
+// tokenIds map token name -> int id
+var tokenIds map[string]int
+
+// lexer object to create a scanner
+var lexer *lexmachine.Lexer
+
+// Literals returns the operators and punctuation recognized by the lexer
+func Literals() []string {
+	return append([]string(nil), literals...)
+}
+
+// Keywords returns the reserved words recognized by the lexer
+func Keywords() []string {
+	return append([]string(nil), keywords...)
+}
+
+// Tokens returns the names of every token type, including the literals and keywords
+func Tokens() []string {
+	return append([]string(nil), tokenNames...)
+}
 
 // scanInput runs the lexer on the input and returns a slice of tokens
 func scanInput(input []byte) (tokens []*lexmachine.Token, err error) {
-	scanner, err := Lexer.Scanner(input)
+	scanner, err := lexer.Scanner(input)
 	if err != nil {
 		return
 	}
 
 	for tok, sErr, eos := scanner.Next(); !eos; tok, sErr, eos = scanner.Next() {
-		if _, is := sErr.(*machines.UnconsumedInput); is {
-			err = sErr
+		if ui, is := sErr.(*machines.UnconsumedInput); is {
+			end := ui.FailTC
+			if end <= ui.StartTC {
+				end = ui.StartTC + 1
+			}
+			start := Position{ui.StartLine, ui.StartColumn}
+			err = &SyntaxError{
+				Code:    CodeSyntax,
+				Message: fmt.Sprintf("unrecognized input '%s'", ui.Text[ui.StartTC:end]),
+				Token:   string(ui.Text[ui.StartTC:end]),
+				Span:    Span{start, start},
+			}
 			return
 		} else if sErr != nil {
 			err = sErr
@@ -49,7 +76,7 @@ func scanInput(input []byte) (tokens []*lexmachine.Token, err error) {
 
 // tokenTypeName takes the token.Type and returns the corresponding string
 func tokenTypeName(token *lexmachine.Token) (tokenType string) {
-	tokenType = Tokens[token.Type]
+	tokenType = tokenNames[token.Type]
 	return
 }
 
@@ -57,14 +84,14 @@ func tokenTypeName(token *lexmachine.Token) (tokenType string) {
 func init() {
 	initTokens()
 	var err error
-	Lexer, err = initLexer()
+	lexer, err = initLexer()
 	if err != nil {
 		panic(err)
 	}
 }
 
 func initTokens() {
-	Literals = []string{
+	literals = []string{
 		// in order of operator precedence
 		"(",
 		")",
@@ -87,7 +114,7 @@ func initTokens() {
 		"OR",
 		",",
 	}
-	Keywords = []string{
+	keywords = []string{
 		":EXT",
 		":INFA",
 		":LKP",
@@ -120,65 +147,78 @@ func initTokens() {
 		"STOPPED",
 		"SUCCEEDED",
 	}
-	Tokens = []string{
+	tokenNames = []string{
 		"PARAM",
 		"STRING",
 		"NUMBER",
 		"IDENT",
 	}
-	Tokens = append(Tokens, Literals...)
-	Tokens = append(Tokens, Keywords...)
-	TokenIds = make(map[string]int)
-	for i, tok := range Tokens {
-		TokenIds[tok] = i
+	tokenNames = append(tokenNames, literals...)
+	tokenNames = append(tokenNames, keywords...)
+	tokenIds = make(map[string]int)
+	for i, tok := range tokenNames {
+		tokenIds[tok] = i
 	}
 }
 
-func initLexer() (lexer *lexmachine.Lexer, err error) {
-	lexer = lexmachine.NewLexer()
+func initLexer() (l *lexmachine.Lexer, err error) {
+	l = lexmachine.NewLexer()
 
 	// Add tokens to lexer
 	// the lexer chooses which rule to use by:
 	//   1. pattern which matches the longest prefix
 	//   2. pattern which was defined first
-	for _, lit := range Literals {
+	for _, lit := range literals {
 		r := "\\" + strings.Join(strings.Split(lit, ""), "\\")
-		lexer.Add([]byte(r), token(lit))
+		l.Add([]byte(r), token(lit))
 	}
-	for _, name := range Keywords {
-		lexer.Add([]byte(name), token(name))
+	for _, name := range keywords {
+		l.Add([]byte(name), token(name))
 	}
 
 	// Tokens by regular expression
 	// You can test here: https://regoio.herokuapp.com/
 
 	// Comment
-	lexer.Add([]byte(`(--|//)[^\n]*\n?`), skip)
+	l.Add([]byte(`(--|//)[^\n]*\n?`), skip)
 	// Parameter/Variable
-	lexer.Add([]byte(`(\$)+([a-z]|[A-Z]|[0-9]|_|\-)+`), token("PARAM"))
+	l.Add([]byte(`(\$)+([a-z]|[A-Z]|[0-9]|_|\-)+`), token("PARAM"))
 	// String
 	// parameters can exist inside strings so we'll need to check for them later during parsing
-	lexer.Add([]byte(`'`), func(scan *lexmachine.Scanner, match *machines.Match) (interface{}, error) {
+	l.Add([]byte(`'`), func(scan *lexmachine.Scanner, match *machines.Match) (interface{}, error) {
+		line, column := match.StartLine, match.StartColumn
 		for tc := scan.TC; tc < len(scan.Text); tc++ {
-			if scan.Text[tc] == '\'' && tc+1 < len(scan.Text) {
+			if scan.Text[tc] == '\n' {
+				line, column = line+1, 0
+			}
+			column++
+			if scan.Text[tc] == '\'' {
 				m := string(scan.Text[scan.TC:tc])
+				token := scan.Token(tokenIds["STRING"], m, match)
+				token.Lexeme = scan.Text[match.TC : tc+1]
+				token.EndLine, token.EndColumn = line, column
 				scan.TC = tc + 1 // move the scanner past the matched string
-				return scan.Token(TokenIds["STRING"], m, match), nil
+				return token, nil
 			}
 		}
-		return nil,
-			fmt.Errorf("unclosed string starting at %d, (%d, %d)",
-				match.TC, match.StartLine, match.StartColumn)
+		start := Position{match.StartLine, match.StartColumn}
+		return nil, &SyntaxError{
+			Code:    CodeSyntax,
+			Message: "unclosed string",
+			Token:   string(scan.Text[match.TC:]),
+			Span:    Span{start, Position{line, column}},
+		}
 	})
 	// Number
-	lexer.Add([]byte(`-?[0-9]+(\.?[0-9]+)*`), token("NUMBER"))
+	// a leading "-" is an operator, not part of the number, so that a-1 and 5-3 are subtractions
+	l.Add([]byte(`([0-9]+(\.[0-9]*)?)|(\.[0-9]+)`), token("NUMBER"))
 	// Identifier
 	// Because go doesn't support lookaheads completely, functions are also matched here
-	lexer.Add([]byte(`([a-z]|[A-Z]|_|[0-9])+`), token("IDENT"))
+	l.Add([]byte(`([a-z]|[A-Z]|_|[0-9])+`), token("IDENT"))
 	// Whitespace
-	lexer.Add([]byte(`( |\t|\n|\r)+`), skip) // skip whitespace
+	l.Add([]byte(`( |\t|\n|\r)+`), skip) // skip whitespace
 
-	err = lexer.Compile()
+	err = l.Compile()
 
 	return
 }
@@ -186,7 +226,7 @@ func initLexer() (lexer *lexmachine.Lexer, err error) {
 // token constructs a Token of the given token type by the token type's name
 func token(name string) lexmachine.Action {
 	return func(s *lexmachine.Scanner, m *machines.Match) (t interface{}, err error) {
-		t = s.Token(TokenIds[name], string(m.Bytes), m)
+		t = s.Token(tokenIds[name], string(m.Bytes), m)
 		err = nil
 		return
 	}
@@ -6,7 +6,7 @@ import (
 )
 
 func TestTokenTypeName(t *testing.T) {
-	input := strings.Join(Tokens, "")
+	input := strings.Join(Tokens(), "")
 
 	tokens, err := scanInput([]byte(input))
 	if err != nil {
@@ -21,14 +21,14 @@ func TestTokenTypeName(t *testing.T) {
 }
 
 func TestLiterals(t *testing.T) {
-	input := strings.Join(Literals, " ")
+	input := strings.Join(Literals(), " ")
 
 	tokens, err := scanInput([]byte(input))
 	if err != nil {
 		t.Error(err)
 	}
 
-	expect := Literals
+	expect := Literals()
 
 	if len(expect) != len(tokens) {
 		t.Errorf("Got different number of tokens: %d instead of %d", len(tokens), len(expect))
@@ -42,13 +42,13 @@ func TestLiterals(t *testing.T) {
 }
 
 func TestKeywords(t *testing.T) {
-	input := strings.Join(Keywords, " ")
+	input := strings.Join(Keywords(), " ")
 	tokens, err := scanInput([]byte(input))
 	if err != nil {
 		t.Error(err)
 	}
 
-	expect := Keywords
+	expect := Keywords()
 
 	if len(expect) != len(tokens) {
 		t.Errorf("Got different number of tokens: %d instead of %d", len(tokens), len(expect))
@@ -105,3 +105,37 @@ func TestSimpleExpression(t *testing.T) {
 		}
 	}
 }
+
+func TestStrings(t *testing.T) {
+	testCases := []struct {
+		input  string
+		expect []string
+	}{
+		{`'abc'`, []string{"abc"}},
+		{`'a' = 'b'`, []string{"a", "=", "b"}},
+		{`''`, []string{""}},
+	}
+
+	for _, tc := range testCases {
+		tokens, err := scanInput([]byte(tc.input))
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+
+		if len(tokens) != len(tc.expect) {
+			t.Errorf("Got different number of tokens: %d instead of %d", len(tokens), len(tc.expect))
+			continue
+		}
+
+		for i, token := range tokens {
+			if tc.expect[i] != token.Value {
+				t.Errorf("Expected: %s, got: %s", tc.expect[i], token.Value)
+			}
+		}
+	}
+
+	if _, err := scanInput([]byte(`'abc`)); err == nil {
+		t.Error("Expected an unclosed string to be an error")
+	}
+}
@@ -0,0 +1,357 @@
+// mapping variables keep their values from one session to the next, like the values the Integration Service saves to
+// the repository
+// https://docs.informatica.com/data-integration/powercenter/10-4-0/designer-guide/mapping-parameters-and-variables/mapping-variables.html
+
+package expression
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Aggregation is how the value of a mapping variable saved at the end of a session is chosen
+type Aggregation int
+
+// Aggregation types
+const (
+	AggregationMax   Aggregation = iota // the greater of the start value and the final current value
+	AggregationMin                      // the lesser of the start value and the final current value
+	AggregationCount                    // the final current value, counted by SETCOUNTVARIABLE
+)
+
+var aggregationNames = map[Aggregation]string{
+	AggregationMax:   "Max",
+	AggregationMin:   "Min",
+	AggregationCount: "Count",
+}
+
+func (a Aggregation) String() string {
+	return aggregationNames[a]
+}
+
+// MappingVariable declares a mapping variable, e.g. $$LAST_RUN_DATE
+// The Initial value is used when no value has been saved; when it is NULL the default of the datatype is used: 0, an
+// empty string or 1/1/1753
+type MappingVariable struct {
+	Name        string
+	Type        Type
+	Aggregation Aggregation
+	Initial     Value
+}
+
+// MappingVariables holds the values of mapping variables over the runs of a session
+// Each variable has a start value, which expressions see and the SETVARIABLE functions start from, and a current
+// value which they change; Commit saves the current values for the next run as a successful session does
+// It is safe to use from many goroutines
+type MappingVariables struct {
+	mu        sync.Mutex
+	variables map[string]MappingVariable
+	start     Row
+	current   Row
+}
+
+// NewMappingVariables returns a store of the mapping variables with their initial values
+// Names must start with $$ and variables with the Count aggregation must be integers
+func NewMappingVariables(variables ...MappingVariable) (m *MappingVariables, err error) {
+	m = &MappingVariables{variables: map[string]MappingVariable{}, start: Row{}, current: Row{}}
+	for _, v := range variables {
+		switch {
+		case !strings.HasPrefix(v.Name, "$$"):
+			err = fmt.Errorf("the mapping variable %s must start with $$", v.Name)
+		case m.variables[v.Name].Name != "":
+			err = fmt.Errorf("the mapping variable %s is declared more than once", v.Name)
+		case v.Aggregation == AggregationCount && v.Type != TypeInteger:
+			err = fmt.Errorf("the mapping variable %s has the Count aggregation so it must be an integer", v.Name)
+		}
+		if err != nil {
+			m = nil
+			return
+		}
+
+		m.variables[v.Name] = v
+		initial := initialValue(v.Type, 0, 0)
+		if !v.Initial.IsNull() {
+			if initial, err = (Port{Name: v.Name, Type: v.Type}).convert(v.Initial); err != nil {
+				m = nil
+				return
+			}
+		}
+		m.start[v.Name] = initial
+		m.current[v.Name] = initial
+	}
+
+	return
+}
+
+// Declarations returns the names and datatypes of the mapping variables, in order of name
+func (m *MappingVariables) Declarations() (declarations []Declaration) {
+	for _, v := range m.variables {
+		declarations = append(declarations, Declaration{Name: v.Name, Type: v.Type})
+	}
+	sort.Slice(declarations, func(i, j int) bool { return declarations[i].Name < declarations[j].Name })
+
+	return
+}
+
+// declared checks if there is a mapping variable with the name
+func (m *MappingVariables) declared(name string) bool {
+	_, found := m.variables[name]
+	return found
+}
+
+// Start returns the value the variable had at the start of the session; it is NULL if the variable isn't declared
+func (m *MappingVariables) Start(name string) Value {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.start[name]
+}
+
+// Current returns the value of the variable after the SETVARIABLE functions evaluated so far in the session
+func (m *MappingVariables) Current(name string) Value {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.current[name]
+}
+
+// Commit ends a successful session, saving a final value of each variable as its start value for the next run
+// depending on its aggregation: the greater or lesser of the start and current values for Max and Min, or the current
+// value for Count
+func (m *MappingVariables) Commit() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for name, v := range m.variables {
+		final := m.current[name]
+		if v.Aggregation != AggregationCount {
+			c, err := compare("SETVARIABLE", final, m.start[name])
+			if err != nil || v.Aggregation == AggregationMax && c < 0 || v.Aggregation == AggregationMin && c > 0 {
+				final = m.start[name]
+			}
+		}
+		m.start[name] = final
+		m.current[name] = final
+	}
+}
+
+// Rollback ends a failed session, discarding the current values so the next run starts from the same values
+func (m *MappingVariables) Rollback() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for name, v := range m.start {
+		m.current[name] = v
+	}
+}
+
+// Save writes the start values of the variables as a JSON object of their names and values
+// Values are written without losing precision: doubles with all of their digits and dates in RFC 3339 format with
+// nanoseconds, other datatypes the same way as the V of a Variable
+func (m *MappingVariables) Save(w io.Writer) error {
+	m.mu.Lock()
+	saved := map[string]string{}
+	for name, v := range m.start {
+		saved[name] = savedText(v)
+	}
+	m.mu.Unlock()
+
+	b, err := json.MarshalIndent(saved, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(b, '\n'))
+
+	return err
+}
+
+// Load reads values written by Save as the start and current values of the variables; dates can also be in the
+// default date format
+// Variables which aren't in the saved values keep their values, and saved values of variables which aren't declared
+// are an error
+func (m *MappingVariables) Load(r io.Reader) (err error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return
+	}
+	saved := map[string]string{}
+	if err = json.Unmarshal(b, &saved); err != nil {
+		err = fmt.Errorf("the saved mapping variables are invalid: %v", err)
+		return
+	}
+
+	loaded := Row{}
+	for name, text := range saved {
+		v, found := m.variables[name]
+		if !found {
+			err = fmt.Errorf("the saved mapping variable %s isn't declared", name)
+			return
+		}
+		if loaded[name], err = savedValue(name, text, v.Type); err != nil {
+			err = fmt.Errorf("the saved value of %s is invalid: %v", name, err)
+			return
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for name, v := range loaded {
+		m.start[name] = v
+		m.current[name] = v
+	}
+
+	return
+}
+
+// savedText renders a value for Save so that savedValue reads back exactly the same value
+func savedText(v Value) string {
+	switch {
+	case v.IsNull():
+	case v.typ == TypeDouble:
+		return strconv.FormatFloat(v.f, 'g', -1, 64)
+	case v.typ == TypeDate:
+		return v.t.Format(time.RFC3339Nano)
+	}
+
+	return v.String()
+}
+
+// savedValue reads a value written by savedText
+func savedValue(name string, text string, t Type) (v Value, err error) {
+	if t == TypeDate {
+		if d, dErr := time.Parse(time.RFC3339Nano, text); dErr == nil {
+			v = NewDate(d.UTC())
+			return
+		}
+	}
+	v, err = typedValue(Variable{N: name, V: text}, t)
+
+	return
+}
+
+// initialValue is the value of a variable or variable port which hasn't been set, as PowerCenter initializes them
+func initialValue(t Type, precision int, scale int) Value {
+	switch t {
+	case TypeString:
+		return NewString("")
+	case TypeInteger:
+		return NewInteger(0)
+	case TypeBigint:
+		return NewBigint(0)
+	case TypeDecimal:
+		return NewDecimal(0, precision, scale)
+	case TypeDouble:
+		return NewDouble(0)
+	case TypeDate:
+		return NewDate(time.Date(1753, 1, 1, 0, 0, 0, 0, time.UTC))
+	case TypeBinary:
+		return NewBinary([]byte{})
+	}
+
+	return Value{}
+}
+
+// mappingVariable returns the declaration of the mapping variable the first argument of a SETVARIABLE function refers
+// to, or an error if the session has no such variable
+func mappingVariable(fn string, env *environment, args []Node) (m *MappingVariables, v MappingVariable, err error) {
+	m = env.evaluator.options.MappingVariables
+	if args[0].Exp == "IDENT" && m != nil && m.declared(args[0].Name) {
+		v = m.variables[args[0].Name]
+		return
+	}
+	err = fmt.Errorf("the first argument of %s must be a mapping variable declared in the Options", fn)
+
+	return
+}
+
+// setVariable sets the current value of the variable to the value, which is converted to its datatype; a NULL value
+// leaves it unchanged and the current value is returned
+func setVariable(env *environment, args ...Node) (result Value, err error) {
+	return setWith("SETVARIABLE", env, args, func(int) bool { return true })
+}
+
+// setMaxVariable sets the current value of a variable with the Max aggregation to the greater of it and the value
+func setMaxVariable(env *environment, args ...Node) (result Value, err error) {
+	return setWith("SETMAXVARIABLE", env, args, func(c int) bool { return c > 0 })
+}
+
+// setMinVariable sets the current value of a variable with the Min aggregation to the lesser of it and the value
+func setMinVariable(env *environment, args ...Node) (result Value, err error) {
+	return setWith("SETMINVARIABLE", env, args, func(c int) bool { return c < 0 })
+}
+
+// setWith replaces the current value of the variable when replace is true for the comparison of the value with the
+// current value, returning the current value afterwards
+// SETMAXVARIABLE and SETMINVARIABLE are an error for variables with other aggregations, as is SETVARIABLE for Count
+func setWith(fn string, env *environment, args []Node, replace func(c int) bool) (result Value, err error) {
+	m, variable, err := mappingVariable(fn, env, args)
+	if err != nil {
+		return
+	}
+	if fn == "SETMAXVARIABLE" && variable.Aggregation != AggregationMax ||
+		fn == "SETMINVARIABLE" && variable.Aggregation != AggregationMin ||
+		fn == "SETVARIABLE" && variable.Aggregation == AggregationCount {
+		err = fmt.Errorf("%s can't be used with %s, which has the %s aggregation", fn, variable.Name, variable.Aggregation)
+		return
+	}
+
+	v, err := evaluateNode(args[1], env)
+	if err != nil {
+		return
+	}
+	if !v.IsNull() {
+		if v, err = (Port{Name: variable.Name, Type: variable.Type}).convert(v); err != nil {
+			return
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	result = m.current[variable.Name]
+	if v.IsNull() {
+		return
+	}
+	c, err := compare(fn, v, result)
+	if err != nil {
+		return
+	}
+	if replace(c) {
+		m.current[variable.Name] = v
+		result = v
+	}
+
+	return
+}
+
+// setCountVariable adds one to the current value of a variable with the Count aggregation and returns it
+// The Integration Service counts inserted rows and subtracts deleted ones; rows here are always inserted
+func setCountVariable(env *environment, args ...Node) (result Value, err error) {
+	m, variable, err := mappingVariable("SETCOUNTVARIABLE", env, args)
+	if err != nil {
+		return
+	}
+	if variable.Aggregation != AggregationCount {
+		err = fmt.Errorf("SETCOUNTVARIABLE can't be used with %s, which has the %s aggregation", variable.Name,
+			variable.Aggregation)
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if result, err = add(m.current[variable.Name], NewInteger(1)); err != nil {
+		return
+	}
+	if result, err = (Port{Name: variable.Name, Type: TypeInteger}).convert(result); err != nil {
+		return
+	}
+	m.current[variable.Name] = result
+
+	return
+}
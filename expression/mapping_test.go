@@ -0,0 +1,238 @@
+package expression
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newMappingVariables(t *testing.T) *MappingVariables {
+	m, err := NewMappingVariables(
+		MappingVariable{Name: "$$LAST_RUN_DATE", Type: TypeDate, Aggregation: AggregationMax},
+		MappingVariable{Name: "$$LOWEST", Type: TypeInteger, Aggregation: AggregationMin, Initial: NewInteger(100)},
+		MappingVariable{Name: "$$ROWS", Type: TypeInteger, Aggregation: AggregationCount},
+		MappingVariable{Name: "$$REGION", Type: TypeString, Initial: NewString("EAST")},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return m
+}
+
+func TestMappingVariables(t *testing.T) {
+	m := newMappingVariables(t)
+	evaluator := newEvaluator(t, Options{MappingVariables: m})
+	transformation, err := evaluator.NewTransformation([]Port{
+		{Name: "in_UPDATED", Kind: PortInput, Type: TypeDate},
+		{Name: "in_AMOUNT", Kind: PortInput, Type: TypeInteger},
+		{Name: "o_NEW", Kind: PortOutput, Type: TypeInteger, Expression: "IIF(in_UPDATED > $$LAST_RUN_DATE, 1, 0)"},
+		{Name: "o_MAX", Kind: PortOutput, Type: TypeDate, Expression: "SETMAXVARIABLE($$LAST_RUN_DATE, in_UPDATED)"},
+		{Name: "o_MIN", Kind: PortOutput, Type: TypeInteger, Expression: "SETMINVARIABLE($$LOWEST, in_AMOUNT)"},
+		{Name: "o_ROWS", Kind: PortOutput, Type: TypeInteger, Expression: "SETCOUNTVARIABLE($$ROWS)"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	date := func(day int) Value { return NewDate(time.Date(2020, 3, day, 0, 0, 0, 0, time.UTC)) }
+	rows := []Row{
+		{"in_UPDATED": date(2), "in_AMOUNT": NewInteger(150)},
+		{"in_UPDATED": date(5), "in_AMOUNT": NewInteger(40)},
+		{"in_UPDATED": date(3), "in_AMOUNT": Value{}},
+	}
+
+	// the first run sees every row as new
+	expect := []string{
+		"1,03/02/2020 00:00:00.000000,100,1",
+		"1,03/05/2020 00:00:00.000000,40,2",
+		"1,03/05/2020 00:00:00.000000,40,3",
+	}
+	outputs, err := transformation.Run(rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, output := range outputs {
+		if result := outputString(output, "o_NEW", "o_MAX", "o_MIN", "o_ROWS"); result != expect[i] {
+			t.Errorf("Row: %d\nExpected: `%s`, got `%s`", i, expect[i], result)
+		}
+	}
+	if result := m.Start("$$LAST_RUN_DATE").String(); result != "01/01/1753 00:00:00.000000" {
+		t.Errorf("Expected the start value to be unchanged until Commit, got `%s`", result)
+	}
+	m.Commit()
+
+	// the next run, with the saved values, only sees the rows updated after the last run as new
+	var saved bytes.Buffer
+	if err := m.Save(&saved); err != nil {
+		t.Fatal(err)
+	}
+	m = newMappingVariables(t)
+	if err := m.Load(&saved); err != nil {
+		t.Fatal(err)
+	}
+	evaluator = newEvaluator(t, Options{MappingVariables: m})
+	program, err := evaluator.Compile("IIF(in_UPDATED > $$LAST_RUN_DATE, 1, 0)", []Declaration{
+		{Name: "in_UPDATED", Type: TypeDate},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var results []Value
+	for _, row := range append(rows, Row{"in_UPDATED": date(6)}) {
+		result, err := program.Run(row)
+		if err != nil {
+			t.Fatal(err)
+		}
+		results = append(results, result)
+	}
+	if result := valuesString(results); result != "0,0,0,1" {
+		t.Errorf("Expected: `0,0,0,1`, got `%s`", result)
+	}
+
+	for name, expect := range map[string]string{
+		"$$LAST_RUN_DATE": "03/05/2020 00:00:00.000000",
+		"$$LOWEST":        "40",
+		"$$ROWS":          "3",
+		"$$REGION":        "EAST",
+	} {
+		if result := m.Start(name).String(); result != expect {
+			t.Errorf("Variable: %s\nExpected: `%s`, got `%s`", name, expect, result)
+		}
+	}
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	decimal, err := ParseDecimal("12345678901234567890.123456789")
+	if err != nil {
+		t.Fatal(err)
+	}
+	variables := []MappingVariable{
+		{Name: "$$STRING", Type: TypeString, Initial: NewString("it's")},
+		{Name: "$$INTEGER", Type: TypeInteger, Initial: NewInteger(-2147483648)},
+		{Name: "$$BIGINT", Type: TypeBigint, Initial: NewBigint(9223372036854775807)},
+		{Name: "$$DECIMAL", Type: TypeDecimal, Initial: decimal},
+		{Name: "$$DOUBLE", Type: TypeDouble, Initial: NewDouble(0.1234567890123456789)},
+		{Name: "$$BIG_DOUBLE", Type: TypeDouble, Initial: NewDouble(1.2345678901234567e300)},
+		{Name: "$$DATE", Type: TypeDate, Initial: NewDate(time.Date(2021, 6, 15, 10, 30, 45, 123456789, time.UTC))},
+		{Name: "$$BINARY", Type: TypeBinary, Initial: NewBinary([]byte{0, 1, 0xFE})},
+	}
+	saved, err := NewMappingVariables(variables...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b bytes.Buffer
+	if err := saved.Save(&b); err != nil {
+		t.Fatal(err)
+	}
+
+	// the loaded store starts from the defaults of the datatypes
+	for i := range variables {
+		variables[i].Initial = Value{}
+	}
+	loaded, err := NewMappingVariables(variables...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := loaded.Load(&b); err != nil {
+		t.Fatal(err)
+	}
+
+	// every datatype reads back exactly the value that was saved
+	for _, v := range variables {
+		expect, result := saved.Start(v.Name), loaded.Start(v.Name)
+		same := result.typ == expect.typ && result.String() == expect.String()
+		switch v.Type {
+		case TypeDouble:
+			same = result.f == expect.f
+		case TypeDate:
+			same = result.t.Equal(expect.t)
+		}
+		if !same {
+			t.Errorf("Variable: %s\nExpected: `%s`, got `%s`", v.Name, savedText(expect), savedText(result))
+		}
+	}
+}
+
+func TestSETVARIABLE(t *testing.T) {
+	m := newMappingVariables(t)
+	evaluator := newEvaluator(t, Options{MappingVariables: m})
+	testCases := []stringTestCase{
+		{`SETVARIABLE($$REGION, 'WEST')`, `WEST`},
+		{`$$REGION`, `EAST`},
+		{`SETVARIABLE($$REGION, NULL)`, `WEST`},
+		{`SETVARIABLE($$LOWEST, 500)`, `500`},
+		{`SETMINVARIABLE($$LOWEST, '250')`, `250`},
+		{`SETMAXVARIABLE($$LAST_RUN_DATE, '01/02/2021')`, `01/02/2021 00:00:00.000000`},
+		{`SETMAXVARIABLE($$LAST_RUN_DATE, '01/01/2021')`, `01/02/2021 00:00:00.000000`},
+	}
+
+	for _, tc := range testCases {
+		result, err := evaluator.Evaluate(tc.input, nil)
+		if err != nil {
+			t.Errorf("Input: %s\nUnexpected error: %v", tc.input, err)
+			continue
+		}
+		if result.String() != tc.expect {
+			t.Errorf("Input: %s\nExpected: `%s`, got `%s`", tc.input, tc.expect, result.String())
+		}
+	}
+
+	// a Min variable keeps the lesser of its start and final values, a Max variable the greater
+	m.Commit()
+	if result := m.Start("$$LOWEST").String(); result != "100" {
+		t.Errorf("Expected: `100`, got `%s`", result)
+	}
+	if result := m.Start("$$REGION").String(); result != "WEST" {
+		t.Errorf("Expected: `WEST`, got `%s`", result)
+	}
+
+	// a failed session doesn't change the values for the next run
+	if _, err := evaluator.Evaluate(`SETCOUNTVARIABLE($$ROWS)`, nil); err != nil {
+		t.Fatal(err)
+	}
+	m.Rollback()
+	if result := m.Current("$$ROWS").String(); result != "0" {
+		t.Errorf("Expected: `0` after Rollback, got `%s`", result)
+	}
+}
+
+func TestMappingVariableErrors(t *testing.T) {
+	evaluator := newEvaluator(t, Options{MappingVariables: newMappingVariables(t)})
+	for _, input := range []string{
+		`SETMAXVARIABLE($$LOWEST, 1)`,
+		`SETMINVARIABLE($$LAST_RUN_DATE, SYSDATE)`,
+		`SETVARIABLE($$ROWS, 1)`,
+		`SETCOUNTVARIABLE($$REGION)`,
+		`SETVARIABLE('$$REGION', 'a')`,
+		`SETVARIABLE($$LOWEST, 'abc')`,
+		`SETVARIABLE($$UNKNOWN, 1)`,
+	} {
+		if _, err := evaluator.Evaluate(input, nil); err == nil {
+			t.Errorf("Input: %s\nExpected an error", input)
+		}
+	}
+	if _, err := Evaluate(`SETCOUNTVARIABLE($$ROWS)`, []Variable{{N: "$$ROWS", T: "integer", V: "1"}}); err == nil {
+		t.Error("Expected an error without mapping variables in the Options")
+	}
+
+	declarations := [][]MappingVariable{
+		{{Name: "LAST_RUN_DATE", Type: TypeDate}},
+		{{Name: "$$A", Type: TypeString}, {Name: "$$A", Type: TypeString}},
+		{{Name: "$$A", Type: TypeString, Aggregation: AggregationCount}},
+		{{Name: "$$A", Type: TypeInteger, Initial: NewString("abc")}},
+	}
+	for _, variables := range declarations {
+		if _, err := NewMappingVariables(variables...); err == nil {
+			t.Errorf("Variables: %v\nExpected an error", variables)
+		}
+	}
+
+	m := newMappingVariables(t)
+	for _, saved := range []string{`{"$$UNKNOWN": "1"}`, `{"$$ROWS": "abc"}`, `[]`} {
+		if err := m.Load(strings.NewReader(saved)); err == nil {
+			t.Errorf("Saved: %s\nExpected an error", saved)
+		}
+	}
+}
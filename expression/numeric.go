@@ -0,0 +1,331 @@
+// numeric functions
+// https://docs.informatica.com/data-integration/powercenter/10-4-0/transformation-language-reference/functions.html
+
+package expression
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+)
+
+// round rounds a number to precision digits after the decimal point, or a date to a date part
+// Numbers are rounded half away from zero and a negative precision rounds to the left of the decimal point
+func round(args ...Value) (result Value, err error) {
+	if args[0].typ == TypeDate {
+		return roundDate("ROUND", args, true)
+	}
+
+	return roundNumber("ROUND", args, true)
+}
+
+// trunc truncates a number to precision digits after the decimal point, or a date to a date part
+func trunc(args ...Value) (result Value, err error) {
+	if args[0].typ == TypeDate {
+		return roundDate("TRUNC", args, false)
+	}
+
+	return roundNumber("TRUNC", args, false)
+}
+
+// numericNull returns the NULL that a numeric function returns for a NULL argument of type t
+func numericNull(t Type) Value {
+	if !t.IsNumeric() {
+		t = TypeDouble
+	}
+
+	return NewNull(t)
+}
+
+// roundNumber rounds (or truncates when up is false) to precision digits, keeping the type of the number
+func roundNumber(fn string, args []Value, up bool) (result Value, err error) {
+	if anyNull(args) {
+		result = numericNull(args[0].typ)
+		return
+	}
+
+	v, err := toNumeric(fn, args[0])
+	if err != nil {
+		return
+	}
+	precision := 0
+	if len(args) > 1 {
+		if precision, err = toPosition(fn, args[1]); err != nil {
+			return
+		}
+	}
+
+	switch v.typ {
+	case TypeInteger, TypeBigint:
+		if precision >= 0 {
+			result = v
+			return
+		}
+		r := roundRat(new(big.Rat).SetInt64(v.i), precision, up)
+		if !r.IsInt64() {
+			err = &RowError{Function: fn, Message: fmt.Sprintf("%s is out of range", r)}
+			return
+		}
+		result = integerValue(v.typ, r.Int64())
+	case TypeDecimal:
+		scale := precision
+		if scale < 0 {
+			scale = 0
+		} else if scale > v.scale {
+			scale = v.scale
+		}
+		if result, err = exactDecimal(roundedRat(v.rat(), precision, up), scale); err != nil {
+			err = &RowError{Function: fn, Message: err.Error()}
+		}
+	default:
+		result = NewDouble(roundFloat(v.f, precision, up))
+	}
+
+	return
+}
+
+// integerValue returns an integer of type t, or a bigint if it doesn't fit in an integer
+func integerValue(t Type, i int64) Value {
+	if t == TypeInteger && i >= math.MinInt32 && i <= math.MaxInt32 {
+		return NewInteger(int32(i))
+	}
+
+	return NewBigint(i)
+}
+
+// roundFloat rounds f to precision decimal digits
+// The shortest decimal that is the float is rounded, rather than its binary value, so that 1.005 rounds to 1.01
+func roundFloat(f float64, precision int, up bool) float64 {
+	if math.IsInf(f, 0) || math.IsNaN(f) {
+		return f
+	}
+
+	result, _ := roundedRat(NewDouble(f).rat(), precision, up).Float64()
+
+	return result
+}
+
+// roundedRat returns r rounded to precision decimal digits
+func roundedRat(r *big.Rat, precision int, up bool) *big.Rat {
+	rounded := new(big.Rat).SetInt(roundRat(r, precision, up))
+	if precision > 0 {
+		rounded.Quo(rounded, new(big.Rat).SetInt(pow10(precision)))
+	}
+
+	return rounded
+}
+
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+// roundRat returns r * 10^precision rounded to an integer, half away from zero when up is true and towards zero when
+// it is false; for a negative precision the result is multiplied back, so roundRat(15, -1) is 20
+func roundRat(r *big.Rat, precision int, up bool) *big.Int {
+	n := precision
+	if n < 0 {
+		n = -n
+	}
+	scale := pow10(n)
+	scaled := new(big.Rat).Set(r)
+	if precision >= 0 {
+		scaled.Mul(scaled, new(big.Rat).SetInt(scale))
+	} else {
+		scaled.Quo(scaled, new(big.Rat).SetInt(scale))
+	}
+
+	q, rem := new(big.Int).QuoRem(scaled.Num(), scaled.Denom(), new(big.Int))
+	if up && new(big.Int).Mul(new(big.Int).Abs(rem), big.NewInt(2)).Cmp(scaled.Denom()) >= 0 {
+		q.Add(q, big.NewInt(int64(rem.Sign())))
+	}
+	if precision < 0 {
+		q.Mul(q, scale)
+	}
+
+	return q
+}
+
+func ceil(args ...Value) (result Value, err error) {
+	return integral("CEIL", args[0], true)
+}
+
+func floor(args ...Value) (result Value, err error) {
+	return integral("FLOOR", args[0], false)
+}
+
+// integral returns the smallest integer not less than the number when ceiling is true, otherwise the largest integer
+// not greater than it, keeping the type of the number
+func integral(fn string, v Value, ceiling bool) (result Value, err error) {
+	if v.IsNull() {
+		result = numericNull(v.typ)
+		return
+	}
+
+	v, err = toNumeric(fn, v)
+	if err != nil {
+		return
+	}
+
+	switch v.typ {
+	case TypeInteger, TypeBigint:
+		result = v
+	case TypeDecimal:
+		r := v.rat()
+		i := roundRat(r, 0, false)
+		if c := r.Cmp(new(big.Rat).SetInt(i)); ceiling && c > 0 {
+			i.Add(i, big.NewInt(1))
+		} else if !ceiling && c < 0 {
+			i.Sub(i, big.NewInt(1))
+		}
+		result, err = exactDecimal(new(big.Rat).SetInt(i), 0)
+	case TypeDouble:
+		if ceiling {
+			result = NewDouble(math.Ceil(v.f))
+		} else {
+			result = NewDouble(math.Floor(v.f))
+		}
+	}
+
+	return
+}
+
+// mod returns the remainder of dividing the number by divisor, with the sign of the number
+func mod(args ...Value) (result Value, err error) {
+	result, err = modulus(args...)
+	if rErr, ok := err.(*RowError); ok {
+		rErr.Function = "MOD"
+	}
+
+	return
+}
+
+// sign returns -1, 0 or 1 for a negative, zero or positive number
+func sign(args ...Value) (result Value, err error) {
+	if args[0].IsNull() {
+		result = NewNull(TypeInteger)
+		return
+	}
+
+	v, err := toNumeric("SIGN", args[0])
+	if err != nil {
+		return
+	}
+	switch f := v.Float(); {
+	case f < 0:
+		result = NewInteger(-1)
+	case f > 0:
+		result = NewInteger(1)
+	default:
+		result = NewInteger(0)
+	}
+
+	return
+}
+
+// mathFunction returns a function of one number which returns a double, with domain giving the error for an
+// argument it isn't defined for, e.g. the square root of a negative number
+func mathFunction(fn string, f func(float64) float64, domain func(float64) string) func(args ...Value) (Value, error) {
+	return func(args ...Value) (result Value, err error) {
+		if args[0].IsNull() {
+			result = NewNull(TypeDouble)
+			return
+		}
+
+		v, err := toNumeric(fn, args[0])
+		if err != nil {
+			return
+		}
+		if domain != nil {
+			if msg := domain(v.Float()); msg != "" {
+				err = &RowError{Function: fn, Message: msg}
+				return
+			}
+		}
+		result, err = doubleResult(fn, f(v.Float()))
+
+		return
+	}
+}
+
+// doubleResult raises a row error for results which aren't a finite double, e.g. EXP(1000)
+func doubleResult(fn string, f float64) (result Value, err error) {
+	if math.IsInf(f, 0) || math.IsNaN(f) {
+		err = &RowError{Function: fn, Message: "the result is out of range"}
+		return
+	}
+	result = NewDouble(f)
+
+	return
+}
+
+func positive(f float64) string {
+	if f <= 0 {
+		return fmt.Sprintf("%s is not greater than 0", formatDouble(f))
+	}
+
+	return ""
+}
+
+func notNegative(f float64) string {
+	if f < 0 {
+		return fmt.Sprintf("%s is negative", formatDouble(f))
+	}
+
+	return ""
+}
+
+// logarithm returns the logarithm of exponent in base; the base must be positive and not 1, and the exponent positive
+func logarithm(args ...Value) (result Value, err error) {
+	if anyNull(args) {
+		result = NewNull(TypeDouble)
+		return
+	}
+
+	base, err := toNumeric("LOG", args[0])
+	if err != nil {
+		return
+	}
+	exponent, err := toNumeric("LOG", args[1])
+	if err != nil {
+		return
+	}
+	if b := base.Float(); b <= 0 || b == 1 {
+		err = &RowError{Function: "LOG", Message: fmt.Sprintf("the base %s must be greater than 0 and not 1", base)}
+		return
+	}
+	if msg := positive(exponent.Float()); msg != "" {
+		err = &RowError{Function: "LOG", Message: "the exponent " + msg}
+		return
+	}
+	result, err = doubleResult("LOG", math.Log(exponent.Float())/math.Log(base.Float()))
+
+	return
+}
+
+// power raises base to exponent; a negative base needs an integer exponent
+func power(args ...Value) (result Value, err error) {
+	if anyNull(args) {
+		result = NewNull(TypeDouble)
+		return
+	}
+
+	base, err := toNumeric("POWER", args[0])
+	if err != nil {
+		return
+	}
+	exponent, err := toNumeric("POWER", args[1])
+	if err != nil {
+		return
+	}
+	b, e := base.Float(), exponent.Float()
+	if b < 0 && e != math.Trunc(e) {
+		err = &RowError{
+			Function: "POWER",
+			Message:  fmt.Sprintf("the exponent %s must be an integer for the negative base %s", exponent, base),
+		}
+		return
+	}
+	result, err = doubleResult("POWER", math.Pow(b, e))
+
+	return
+}
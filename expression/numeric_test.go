@@ -0,0 +1,113 @@
+package expression
+
+import (
+	"testing"
+)
+
+func TestROUNDTRUNCNumbers(t *testing.T) {
+	runStringTests(t, []stringTestCase{
+		{`ROUND(12.99)`, `13`},
+		{`ROUND(12.5)`, `13`},
+		{`ROUND(-12.5)`, `-13`},
+		{`ROUND(12.99, 1)`, `13`},
+		{`ROUND(15.44, 1)`, `15.4`},
+		{`ROUND(1.005, 2)`, `1.01`},
+		{`ROUND(15.44, 5)`, `15.44`},
+		{`ROUND(1567.99, -2)`, `1600`},
+		{`ROUND(-1567.99, -2)`, `-1600`},
+		{`ROUND(15, -1)`, `20`},
+		{`ROUND(14, -1)`, `10`},
+		{`ROUND(-15, -1)`, `-20`},
+		{`ROUND(15, 2)`, `15`},
+		{`ROUND(2147483647, -1)`, `2147483650`},
+		{`ROUND(12.5, 0.6)`, `12.5`},
+		{`ROUND(NULL)`, `NULL`},
+		{`ROUND(12.5, NULL)`, `NULL`},
+		{`TRUNC(12.99)`, `12`},
+		{`TRUNC(-12.99)`, `-12`},
+		{`TRUNC(12.99, 1)`, `12.9`},
+		{`TRUNC(1567.99, -2)`, `1500`},
+		{`TRUNC(-1567.99, -2)`, `-1500`},
+		{`TRUNC(19, -1)`, `10`},
+		{`TRUNC('12.99')`, `12`},
+	})
+}
+
+func TestCEILFLOOR(t *testing.T) {
+	runStringTests(t, []stringTestCase{
+		{`CEIL(39.79)`, `40`},
+		{`CEIL(-39.79)`, `-39`},
+		{`CEIL(125)`, `125`},
+		{`CEIL(NULL)`, `NULL`},
+		{`FLOOR(39.79)`, `39`},
+		{`FLOOR(-39.79)`, `-40`},
+		{`FLOOR(125)`, `125`},
+		{`FLOOR('3.5')`, `3`},
+		{`FLOOR(NULL)`, `NULL`},
+	})
+}
+
+func TestMOD(t *testing.T) {
+	runStringTests(t, []stringTestCase{
+		{`MOD(10, 3)`, `1`},
+		{`MOD(-10, 3)`, `-1`},
+		{`MOD(10, -3)`, `1`},
+		{`MOD(5.5, 2)`, `1.5`},
+		{`MOD(NULL, 2)`, `NULL`},
+		{`MOD(10, NULL)`, `NULL`},
+		{`MOD(10, 0)`, `<<Expression Error>> [MOD]: divide by zero`},
+	})
+}
+
+func TestPOWERLOG(t *testing.T) {
+	runStringTests(t, []stringTestCase{
+		{`POWER(10, 2)`, `100`},
+		{`POWER(-3.0, 3)`, `-27`},
+		{`POWER(2, -1)`, `0.5`},
+		{`POWER(4, 0.5)`, `2`},
+		{`POWER(NULL, 2)`, `NULL`},
+		{`SQRT(100)`, `10`},
+		{`SQRT(0)`, `0`},
+		{`EXP(0)`, `1`},
+		{`EXP(1)`, `2.71828182845905`},
+		{`LN(1)`, `0`},
+		{`LN(EXP(2))`, `2`},
+		{`LOG(10, 1000)`, `3`},
+		{`LOG(2, 8)`, `3`},
+		{`LOG(NULL, 8)`, `NULL`},
+		{
+			`POWER(-8, 0.5)`,
+			`<<Expression Error>> [POWER]: the exponent 0.5 must be an integer for the negative base -8`,
+		},
+		{`POWER(10, 1000)`, `<<Expression Error>> [POWER]: the result is out of range`},
+		{`SQRT(-1)`, `<<Expression Error>> [SQRT]: -1 is negative`},
+		{`EXP(1000)`, `<<Expression Error>> [EXP]: the result is out of range`},
+		{`LN(0)`, `<<Expression Error>> [LN]: 0 is not greater than 0`},
+		{`LN(-1)`, `<<Expression Error>> [LN]: -1 is not greater than 0`},
+		{`LOG(1, 8)`, `<<Expression Error>> [LOG]: the base 1 must be greater than 0 and not 1`},
+		{`LOG(0, 8)`, `<<Expression Error>> [LOG]: the base 0 must be greater than 0 and not 1`},
+		{`LOG(10, 0)`, `<<Expression Error>> [LOG]: the exponent 0 is not greater than 0`},
+	})
+}
+
+func TestSIGN(t *testing.T) {
+	runStringTests(t, []stringTestCase{
+		{`SIGN(-100)`, `-1`},
+		{`SIGN(0)`, `0`},
+		{`SIGN(0.001)`, `1`},
+		{`SIGN(NULL)`, `NULL`},
+	})
+}
+
+func TestTrigonometry(t *testing.T) {
+	runStringTests(t, []stringTestCase{
+		{`SIN(0)`, `0`},
+		{`SIN(3.14159265358979 / 2)`, `1`},
+		{`COS(0)`, `1`},
+		{`TAN(0)`, `0`},
+		{`SINH(0)`, `0`},
+		{`COSH(0)`, `1`},
+		{`TANH(1)`, `0.761594155955765`},
+		{`COS(NULL)`, `NULL`},
+	})
+}
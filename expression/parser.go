@@ -4,508 +4,557 @@ package expression
 
 import (
 	"fmt"
-	"reflect"
-	"strconv"
 	"strings"
 
 	"github.com/timtadh/lexmachine"
 )
 
-// all Informatica function names
-// https://docs.informatica.com/data-integration/powercenter/10-4-0/transformation-language-reference/functions.html
-var functions map[string]interface{}
-
-func init() {
-	// set the allowed function names
-	functions = map[string]interface{}{
-		"ABORT":            nil,
-		"ABS":              nil,
-		"ADD_TO_DATE":      nil,
-		"AES_DECRYPT":      nil,
-		"AES_ENCRYPT":      nil,
-		"ASCII":            nil,
-		"AVG":              nil,
-		"BINARY_COMPARE":   nil,
-		"BINARY_CONCAT":    nil,
-		"BINARY_LENGTH":    nil,
-		"BINARY_SECTION":   nil,
-		"CEIL":             nil,
-		"CHOOSE":           nil,
-		"CHR":              nil,
-		"CHRCODE":          nil,
-		"COMPRESS":         nil,
-		"CONCAT":           nil,
-		"CONVERT_BASE":     nil,
-		"COS":              nil,
-		"COSH":             nil,
-		"COUNT":            nil,
-		"CRC32":            nil,
-		"CUME":             nil,
-		"DATE_COMPARE":     nil,
-		"DATE_DIFF":        nil,
-		"DEC_BASE64":       nil,
-		"DEC_HEX":          nil,
-		"DECODE":           nil,
-		"DECOMPRESS":       nil,
-		"EBCDIC_ISO88591":  nil,
-		"ENC_BASE64":       nil,
-		"ENC_HEX":          nil,
-		"ERROR":            nil,
-		"EXP":              nil,
-		"FIRST":            nil,
-		"FLOOR":            nil,
-		"FV":               nil,
-		"GET_DATE_PART":    nil,
-		"GREATEST":         nil,
-		"IIF":              nil,
-		"IN":               nil,
-		"INDEXOF":          nil,
-		"INITCAP":          nil,
-		"INSTR":            nil,
-		"ISNULL":           nil,
-		"IS_DATE":          nil,
-		"IS_NUMBER":        nil,
-		"IS_SPACES":        nil,
-		"LAG":              nil,
-		"LAST":             nil,
-		"LAST_DAY":         nil,
-		"LEAD":             nil,
-		"LEAST":            nil,
-		"LENGTH":           nil,
-		"LN":               nil,
-		"LOG":              nil,
-		"LOOKUP":           nil,
-		"LOWER":            nil,
-		"LPAD":             nil,
-		"LTRIM":            nil,
-		"MAKE_DATE_TIME":   nil,
-		"MAX":              nil, // Dates, Numbers, String
-		"MD5":              nil,
-		"MEDIAN":           nil,
-		"METAPHONE":        nil,
-		"MIN":              nil, // Dates, Numbers, String
-		"MOD":              nil,
-		"MOVINGAVG":        nil,
-		"MOVINGSUM":        nil,
-		"NPER":             nil,
-		"PERCENTILE":       nil,
-		"PMT":              nil,
-		"POWER":            nil,
-		"PV":               nil,
-		"RAND":             nil,
-		"RATE":             nil,
-		"REG_EXTRACT":      nil,
-		"REG_MATCH":        nil,
-		"REG_REPLACE":      nil,
-		"REPLACECHR":       nil,
-		"REPLACESTR":       nil,
-		"REVERSE":          nil,
-		"ROUND":            nil, // Dates, Numbers
-		"RPAD":             nil,
-		"RTRIM":            nil,
-		"SETCOUNTVARIABLE": nil,
-		"SET_DATE_PART":    nil,
-		"SETMAXVARIABLE":   nil,
-		"SETMINVARIABLE":   nil,
-		"SETVARIABLE":      nil,
-		"SHA256":           nil,
-		"SIGN":             nil,
-		"SIN":              nil,
-		"SINH":             nil,
-		"SOUNDEX":          nil,
-		"SQRT":             nil,
-		"STDDEV":           nil,
-		"SUBSTR":           nil,
-		"SUM":              nil,
-		"SYSTIMESTAMP":     nil,
-		"TAN":              nil,
-		"TANH":             nil,
-		"TIME_RANGE":       nil,
-		"TO_BIGINT":        nil,
-		"TO_CHAR":          nil, // Dates, Number
-		"TO_DATE":          nil,
-		"TO_DECIMAL":       nil,
-		"TO_FLOAT":         nil,
-		"TO_INTEGER":       nil,
-		"TRUNC":            nil, // Dates, Number
-		"UPPER":            nil,
-		"VARIANCE":         nil,
-	}
-}
-
 // Node is a node in the AST; nodes may be nested
+// Exp is the function or operator name, VALUE for a literal or substituted Variable, or IDENT for a declared port
 type Node struct {
-	Exp  string
-	Args []interface{}
+	Exp   string
+	Name  string // the name of the port or parameter when Exp is IDENT
+	Args  []Node
+	Value Value // set when Exp is VALUE; when Exp is IDENT it is a NULL of the declared type
+	Span  Span  // where the node is in the input
+}
+
+// Position is a location in the input; lines and columns start at 1
+type Position struct {
+	Line   int
+	Column int
+}
+
+// Span is the range of the input, inclusive, that a Node was parsed from
+type Span struct {
+	Start Position
+	End   Position
+}
+
+// the values of the update strategy constants
+var updateStrategies = map[string]int32{
+	"DD_INSERT": 0,
+	"DD_UPDATE": 1,
+	"DD_DELETE": 2,
+	"DD_REJECT": 3,
 }
 
 // Variable is an IDENT that is substituted during parsing
 type Variable struct {
 	N string // name
 	T string // type
-	V string // value
+	V string // value, a BINARY is written in hex digits, e.g. 48656C6C6F
 }
 
-// parse a string into an AST
+// binary operators and their precedence; higher binds tighter
+// https://docs.informatica.com/data-integration/powercenter/10-4-0/transformation-language-reference/operators.html
+var precedence = map[string]int{
+	"OR":  1,
+	"AND": 2,
+	"=":   3,
+	"<>":  3,
+	"!=":  3,
+	"^=":  3,
+	"<":   4,
+	"<=":  4,
+	">":   4,
+	">=":  4,
+	"||":  5,
+	"+":   6,
+	"-":   6,
+	"*":   7,
+	"/":   7,
+	"%":   7,
+}
+
+// parser holds the state while converting tokens to an AST
+type parser struct {
+	tokens   []*lexmachine.Token
+	pos      int
+	vars     []Variable    // substituted with their values
+	decls    []Declaration // left as IDENT nodes to be bound later
+	errs     []*SyntaxError
+	warnings []*SyntaxError
+}
+
+// parse a string into an AST, returning the first error found
 func parse(input []byte, vars []Variable) (node Node, err error) {
+	node, errs, _ := parseAll(input, vars, nil)
+	if len(errs) > 0 {
+		err = errs[0]
+	}
+
+	return
+}
+
+// parseAll parses a string into an AST and returns every error found rather than stopping at the first
+// Errors which don't affect the structure of the AST (e.g. an unknown identifier) are recorded and parsing continues,
+// and a syntax error in a function's argument is recovered from by skipping to the next argument
+func parseAll(input []byte, vars []Variable, decls []Declaration) (node Node, errs []*SyntaxError,
+	warnings []*SyntaxError) {
 	// Tokenize
 	tokens, err := scanInput(input)
 	if err != nil {
+		if sErr, ok := err.(*SyntaxError); ok {
+			errs = append(errs, sErr)
+		} else {
+			errs = append(errs, &SyntaxError{Code: CodeSyntax, Message: err.Error()})
+		}
 		return
 	}
 
 	// Convert to AST
-	nodes, endPos, err := parseExpression(tokens, vars, 0)
+	p := &parser{tokens: tokens, vars: vars, decls: decls}
+	node, err = p.parseExpression(0)
+	if err == nil && p.pos < len(p.tokens) {
+		// everything should have been consumed by the expression
+		err = p.unexpected()
+	}
+	if err != nil {
+		p.errs = append(p.errs, err.(*SyntaxError))
+	}
+
+	errs, warnings = p.errs, p.warnings
 
+	return
+}
+
+// parseExpression parses operands joined by binary operators which bind at least as tightly as minPrecedence
+// This is precedence climbing: each loop consumes one operator and parses its right hand side with a higher
+// minimum precedence so that operators of equal precedence are left associative (e.g. 10 - 4 - 3)
+func (p *parser) parseExpression(minPrecedence int) (node Node, err error) {
+	node, err = p.parseUnary()
 	if err != nil {
 		return
 	}
 
-	if len(nodes) > 1 {
-		err = fmt.Errorf("couldn't flatten down to one node:\n%+v", nodes)
-		return
+	for p.pos < len(p.tokens) {
+		op := tokenTypeName(p.tokens[p.pos])
+		prec, ok := precedence[op]
+		if !ok || prec < minPrecedence {
+			return
+		}
+		p.pos++
+
+		right, rErr := p.parseExpression(prec + 1)
+		if rErr != nil {
+			err = rErr
+			return
+		}
+
+		node = Node{
+			Exp:  op,
+			Args: []Node{node, right},
+			Span: Span{node.Span.Start, right.Span.End},
+		}
+		p.checkOperator(node)
 	}
 
-	if endPos < len(tokens)-1 {
-		err = fmt.Errorf("Parsing tokens ended at %d, but expected %d", endPos, len(tokens)-1)
+	return
+}
+
+// parseUnary parses "+", "-", and "NOT" which bind tighter than any binary operator
+func (p *parser) parseUnary() (node Node, err error) {
+	if p.pos < len(p.tokens) {
+		token := p.tokens[p.pos]
+		switch op := tokenTypeName(token); op {
+		case "+", "-", "NOT":
+			p.pos++
+			if op == "-" && p.pos < len(p.tokens) && tokenTypeName(p.tokens[p.pos]) == "NUMBER" {
+				// the sign is part of a negative literal, so -2147483648 is an integer rather than a bigint
+				number := p.tokens[p.pos]
+				v, nErr := parseNumber("-" + number.Value.(string))
+				if nErr == nil {
+					p.pos++
+					node = Node{Exp: "VALUE", Value: v, Span: Span{tokenSpan(token).Start, tokenSpan(number).End}}
+					return
+				}
+			}
+			operand, oErr := p.parseUnary()
+			if oErr != nil {
+				err = oErr
+				return
+			}
+			node = unaryNode(op, operand)
+			node.Span.Start = tokenSpan(token).Start
+			return
+		}
+	}
+
+	return p.parsePrimary()
+}
+
+// parsePrimary parses a value, variable, function call, or parenthesized expression
+func (p *parser) parsePrimary() (node Node, err error) {
+	if p.pos >= len(p.tokens) {
+		err = p.unexpected()
 		return
 	}
 
-	node = nodes[0]
+	token := p.tokens[p.pos]
+	tokenType := tokenTypeName(token)
+	value := token.Value.(string)
+	node.Span = tokenSpan(token)
+
+	switch tokenType {
+	case "(":
+		p.pos++
+		inner, iErr := p.parseExpression(0)
+		if iErr != nil {
+			err = iErr
+			return
+		}
+		if err = p.expect(")"); err != nil {
+			return
+		}
+		node = inner
+	case "IDENT":
+		if p.pos+1 < len(p.tokens) && tokenTypeName(p.tokens[p.pos+1]) == "(" {
+			node, err = p.parseCall()
+			return
+		}
+		if isFunction(value) {
+			err = &SyntaxError{
+				Code:    CodeSyntax,
+				Message: fmt.Sprintf("expected '(' after %s", value),
+				Token:   value,
+				Span:    node.Span,
+			}
+			return
+		}
+		node, err = p.variableNode(value, node.Span)
+		p.pos++
+	case "PARAM":
+		node, err = p.variableNode(value, node.Span)
+		p.pos++
+	case "NUMBER":
+		v, nErr := parseNumber(value)
+		if nErr != nil {
+			err = &SyntaxError{Code: CodeSyntax, Message: nErr.Error(), Token: value, Span: node.Span}
+			return
+		}
+		node.Exp, node.Value = "VALUE", v
+		p.pos++
+	case "STRING":
+		node = p.stringNode(value, node.Span)
+		p.pos++
+	case "NULL":
+		node.Exp, node.Value = "VALUE", Value{}
+		p.pos++
+	case "TRUE", "FALSE": // booleans are the integers 1 and 0
+		node.Exp, node.Value = "VALUE", boolValue(tokenType == "TRUE")
+		p.pos++
+	case "DD_INSERT", "DD_UPDATE", "DD_DELETE", "DD_REJECT": // update strategy constants
+		node.Exp, node.Value = "VALUE", NewInteger(updateStrategies[tokenType])
+		p.pos++
+	default:
+		if isLiteral(tokenType) || strings.HasPrefix(tokenType, ":") {
+			err = p.unexpected()
+			return
+		}
+		// keywords such as SYSDATE which are evaluated like a function without arguments
+		node.Exp = tokenType
+		p.pos++
+	}
 
 	return
 }
 
-// parse the expression starting at the given position (recursively) into a Node
-func parseExpression(tokens []*lexmachine.Token, vars []Variable, pos int) (output []Node, endPos int, err error) {
-	// Here we loop through the tokens multiple times and convert each piece to a (possibly nested) Node
-	// Buffers are used to hold intermediate results between passes
-	// Passes should be in order of operator precedence (e.g. * before +)
-	buffer := make([]interface{}, 0)
-
-	// First pass for parentheses, functions, and variable substitution
-paren: // label used to escape for loop
-	for pos < len(tokens) {
-		tokenType := tokenTypeName(tokens[pos])
-		value := tokens[pos].Value.(string)
-		switch tokenType {
-		case ",": // skip commas and move the position forward
-			pos++
-		case ")": // we hit the end of the current parenthesis
-			break paren // escape for loop
-		case "(": // start a new parenthesis, nested node here
-			nodes, end, cErr := parseExpression(tokens, vars, pos+1)
-			if cErr != nil {
-				err = cErr
+// parseCall parses a function name followed by a comma separated list of arguments in parentheses
+func (p *parser) parseCall() (node Node, err error) {
+	name := p.tokens[p.pos]
+	node.Exp = name.Value.(string)
+	node.Span = tokenSpan(name)
+	node.Args = make([]Node, 0)
+
+	known := isFunction(node.Exp)
+	if !known {
+		p.errs = append(p.errs, &SyntaxError{
+			Code:    CodeUnknownFunction,
+			Message: fmt.Sprintf("the function %s does not exist", node.Exp),
+			Token:   node.Exp,
+			Span:    node.Span,
+		})
+	}
+	p.pos += 2 // the name and "("
+
+	// no arguments
+	if p.pos < len(p.tokens) && tokenTypeName(p.tokens[p.pos]) == ")" {
+		node.Span.End = tokenSpan(p.tokens[p.pos]).End
+		p.pos++
+		if known {
+			p.checkCall(node)
+		}
+		return
+	}
+
+	for {
+		var arg Node
+		var aErr error
+		if node.Exp == "COUNT" && len(node.Args) == 0 && p.isCountAll() {
+			// COUNT(*) counts every row, so the * is a value which is never NULL
+			arg = Node{Exp: "VALUE", Value: NewInteger(1), Span: tokenSpan(p.tokens[p.pos])}
+			p.pos++
+		} else {
+			arg, aErr = p.parseExpression(0)
+		}
+		if aErr != nil {
+			// record the error and skip to the next argument
+			if err = p.recover(aErr); err != nil {
 				return
 			}
-			for _, n := range nodes {
-				buffer = append(buffer, n)
-			}
-			pos = end + 1
-		case "IDENT":
-			if isFunction(value) { // nested node here
-				if tokens[pos+1].Value.(string) == "(" {
-					node := Node{value, make([]interface{}, 0)}
-					iNodes, end, cErr := parseExpression(tokens, vars, pos+2)
-					if cErr != nil {
-						err = cErr
-						return
-					}
-					for _, n := range iNodes {
-						node.Args = append(node.Args, n)
-					}
-					buffer = append(buffer, node)
-					pos = end + 1
-				} else {
-					err = fmt.Errorf("expected '(' after %s", value)
-					return
-				}
-			} else { // it's a Variable
-				for _, v := range vars {
-					if value == v.N {
-						node := Node{v.T, make([]interface{}, 0)}
-						if v.T == "NUMBER" {
-							f, fErr := reformatFloat(v.V)
-							if fErr != nil {
-								err = fErr
-								return
-							}
-							node.Args = append(node.Args, f)
-						} else {
-							node.Args = append(node.Args, v.V)
-						}
-						buffer = append(buffer, node)
-						pos++
-						goto paren // continue for loop at next token
-					}
-				}
+			known = false // the arguments can't be checked when one of them is missing
+		}
+		node.Args = append(node.Args, arg)
 
-				err = fmt.Errorf("the identifier '%s' was not found", value)
-				return
+		if p.pos >= len(p.tokens) {
+			err = p.unexpected()
+			return
+		}
+		token := p.tokens[p.pos]
+		switch tokenTypeName(token) {
+		case ",":
+			p.pos++
+		case ")":
+			node.Span.End = tokenSpan(token).End
+			p.pos++
+			if known {
+				p.checkCall(node)
 			}
-		case "NUMBER": // reformat the float (e.g. 2 => 2.000000)
-			f, fErr := reformatFloat(value)
-			if fErr != nil {
-				err = fErr
+			return
+		default:
+			sErr := &SyntaxError{
+				Code:    CodeSyntax,
+				Message: fmt.Sprintf("expected ',' or ')' in the arguments to %s but found '%s'", node.Exp, token.Lexeme),
+				Token:   string(token.Lexeme),
+				Span:    tokenSpan(token),
+			}
+			if err = p.recover(sErr); err != nil {
 				return
 			}
-			node := Node{tokenType, make([]interface{}, 0)}
-			node.Args = append(node.Args, f)
-			buffer = append(buffer, node)
-			pos++
-		case "STRING":
-			// Replace any params/vars with their value
-			for _, v := range vars {
-				if strings.HasPrefix(v.N, "$") {
-					value = strings.ReplaceAll(value, v.N, v.V)
-				}
+			known = false
+			if tokenTypeName(p.tokens[p.pos]) == "," {
+				p.pos++
+			} else {
+				// the call ends at the ")"
+				node.Span.End = tokenSpan(p.tokens[p.pos]).End
+				p.pos++
+				return
 			}
-			node := Node{tokenType, make([]interface{}, 0)}
-			node.Args = append(node.Args, value)
-			buffer = append(buffer, node)
-			pos++
-		default:
-			// misc type that doesn't need further processing right now
-			node := Node{tokenType, make([]interface{}, 0)}
-			node.Args = append(node.Args, value)
-			buffer = append(buffer, node)
-			pos++
 		}
 	}
-	// set the endPos now since we've done a pass thru the original tokens
-	// further passes will be on the buffers and so the end positions will be irrelevant to the function caller
-	endPos = pos
-
-	// "*", "/", "%"
-	bufferNew := make([]interface{}, 0)
-	pos = 0
-	for pos < len(buffer) {
-		switch buffer[pos].(type) {
-		case Node:
-			switch buffer[pos].(Node).Exp {
-			case "*", "/", "%":
-				node := Node{
-					Exp: buffer[pos].(Node).Exp,
-					Args: []interface{}{
-						bufferNew[len(bufferNew)-1],
-						buffer[pos+1],
-					},
-				}
-				bufferNew[len(bufferNew)-1] = node
-				pos += 2
-			default:
-				bufferNew = append(bufferNew, buffer[pos])
-				pos++
+}
+
+// isCountAll checks for the * of COUNT(*) or COUNT(*, filter_condition)
+func (p *parser) isCountAll() bool {
+	if p.pos+1 >= len(p.tokens) || tokenTypeName(p.tokens[p.pos]) != "*" {
+		return false
+	}
+	next := tokenTypeName(p.tokens[p.pos+1])
+
+	return next == ")" || next == ","
+}
+
+// recover records an error in a function argument and moves to the end of the argument
+// The error is returned if the end of the input is reached first, so that parsing stops
+func (p *parser) recover(err error) error {
+	if !p.skipArgument() {
+		return err
+	}
+	p.errs = append(p.errs, err.(*SyntaxError))
+
+	return nil
+}
+
+// skipArgument moves past the rest of a function argument that has an error, stopping before the "," or ")" that
+// ends it; false is returned if the end of the input is reached first
+func (p *parser) skipArgument() bool {
+	depth := 0
+	for ; p.pos < len(p.tokens); p.pos++ {
+		switch tokenTypeName(p.tokens[p.pos]) {
+		case "(":
+			depth++
+		case ")":
+			if depth == 0 {
+				return true
+			}
+			depth--
+		case ",":
+			if depth == 0 {
+				return true
 			}
-		default:
-			bufferNew = append(bufferNew, buffer[pos])
-			pos++
 		}
 	}
 
-	// "+", "-"
-	buffer = nil
-	buffer = append(buffer, bufferNew...)
-	bufferNew = nil
-	pos = 0
-	for pos < len(buffer) {
-		switch buffer[pos].(type) {
-		case Node:
-			switch buffer[pos].(Node).Exp {
-			case "+", "-":
-				node := Node{
-					Exp: buffer[pos].(Node).Exp,
-					Args: []interface{}{
-						bufferNew[len(bufferNew)-1],
-						buffer[pos+1],
-					},
-				}
-				bufferNew[len(bufferNew)-1] = node
-				pos += 2
-			default:
-				bufferNew = append(bufferNew, buffer[pos])
-				pos++
+	return false
+}
+
+// checkCall records an error if the arguments don't match the function's signature
+func (p *parser) checkCall(node Node) {
+	if err := functions[node.Exp].checkArgs(node); err != nil {
+		p.errs = append(p.errs, err)
+	}
+}
+
+// checkOperator records a warning for comparisons which are always NULL, e.g. a = NULL rather than ISNULL(a)
+func (p *parser) checkOperator(node Node) {
+	switch node.Exp {
+	case "=", "<>", "!=", "^=", "<", "<=", ">", ">=":
+		for _, arg := range node.Args {
+			if arg.Exp == "VALUE" && arg.Value.typ == TypeNull {
+				p.warnings = append(p.warnings, &SyntaxError{
+					Code:    CodeNullComparison,
+					Message: fmt.Sprintf("comparing with NULL using %s is always NULL, use ISNULL instead", node.Exp),
+					Token:   node.Exp,
+					Span:    node.Span,
+				})
+				return
 			}
-		default:
-			bufferNew = append(bufferNew, buffer[pos])
-			pos++
 		}
 	}
+}
 
-	// "||"
-	buffer = nil
-	buffer = append(buffer, bufferNew...)
-	bufferNew = nil
-	pos = 0
-	for pos < len(buffer) {
-		switch buffer[pos].(type) {
-		case Node:
-			switch buffer[pos].(Node).Exp {
-			case "||":
-				node := Node{
-					Exp: buffer[pos].(Node).Exp,
-					Args: []interface{}{
-						bufferNew[len(bufferNew)-1],
-						buffer[pos+1],
-					},
-				}
-				bufferNew[len(bufferNew)-1] = node
-				pos += 2
-			default:
-				bufferNew = append(bufferNew, buffer[pos])
-				pos++
-			}
-		default:
-			bufferNew = append(bufferNew, buffer[pos])
-			pos++
+// expect consumes the next token if it has the given type, otherwise it is an error
+func (p *parser) expect(tokenType string) error {
+	if p.pos >= len(p.tokens) || tokenTypeName(p.tokens[p.pos]) != tokenType {
+		err := p.unexpected()
+		err.Message = fmt.Sprintf("expected '%s' but %s", tokenType, err.Message)
+		return err
+	}
+	p.pos++
+
+	return nil
+}
+
+// unexpected returns an error for the current token, or the end of the input if all tokens are consumed
+func (p *parser) unexpected() *SyntaxError {
+	if p.pos >= len(p.tokens) {
+		err := &SyntaxError{Code: CodeSyntax, Message: "reached the end of the expression"}
+		if len(p.tokens) > 0 {
+			end := tokenSpan(p.tokens[len(p.tokens)-1]).End
+			err.Span = Span{end, end}
 		}
+		return err
 	}
 
-	// "<", "<=", ">", ">="
-	buffer = nil
-	buffer = append(buffer, bufferNew...)
-	bufferNew = nil
-	pos = 0
-	for pos < len(buffer) {
-		switch buffer[pos].(type) {
-		case Node:
-			switch buffer[pos].(Node).Exp {
-			case "<", "<=", ">", ">=":
-				node := Node{
-					Exp: buffer[pos].(Node).Exp,
-					Args: []interface{}{
-						bufferNew[len(bufferNew)-1],
-						buffer[pos+1],
-					},
-				}
-				bufferNew[len(bufferNew)-1] = node
-				pos += 2
-			default:
-				bufferNew = append(bufferNew, buffer[pos])
-				pos++
+	token := p.tokens[p.pos]
+	return &SyntaxError{
+		Code:    CodeSyntax,
+		Message: fmt.Sprintf("found unexpected '%s'", token.Lexeme),
+		Token:   string(token.Lexeme),
+		Span:    tokenSpan(token),
+	}
+}
+
+// tokenSpan returns where the token is in the input
+func tokenSpan(token *lexmachine.Token) Span {
+	return Span{
+		Start: Position{token.StartLine, token.StartColumn},
+		End:   Position{token.EndLine, token.EndColumn},
+	}
+}
+
+// variableNode substitutes the named variable with its value, or refers to the declared port or parameter
+// An unknown name is recorded as an error and an IDENT of an unknown type is returned so that parsing can continue
+func (p *parser) variableNode(name string, span Span) (node Node, err error) {
+	for _, v := range p.vars {
+		if name == v.N {
+			value, vErr := variableValue(v)
+			if vErr != nil {
+				p.errs = append(p.errs, &SyntaxError{
+					Code:    CodeInvalidValue,
+					Message: fmt.Sprintf("the value of '%s' is invalid: %v", name, vErr),
+					Token:   name,
+					Span:    span,
+				})
 			}
-		default:
-			bufferNew = append(bufferNew, buffer[pos])
-			pos++
+			node = Node{Exp: "VALUE", Value: value, Span: span}
+			return
 		}
 	}
 
-	// "=", "<>", "!=", "^="
-	buffer = nil
-	buffer = append(buffer, bufferNew...)
-	bufferNew = nil
-	pos = 0
-	for pos < len(buffer) {
-		switch buffer[pos].(type) {
-		case Node:
-			switch buffer[pos].(Node).Exp {
-			case "=", "<>", "!=", "^=":
-				node := Node{
-					Exp: buffer[pos].(Node).Exp,
-					Args: []interface{}{
-						bufferNew[len(bufferNew)-1],
-						buffer[pos+1],
-					},
-				}
-				bufferNew[len(bufferNew)-1] = node
-				pos += 2
-			default:
-				bufferNew = append(bufferNew, buffer[pos])
-				pos++
-			}
-		default:
-			bufferNew = append(bufferNew, buffer[pos])
-			pos++
+	node = Node{Exp: "IDENT", Name: name, Span: span}
+	for _, d := range p.decls {
+		if name == d.Name {
+			node.Value = NewNull(d.Type)
+			return
 		}
 	}
 
-	// "AND"
-	buffer = nil
-	buffer = append(buffer, bufferNew...)
-	bufferNew = nil
-	pos = 0
-	for pos < len(buffer) {
-		switch buffer[pos].(type) {
-		case Node:
-			switch buffer[pos].(Node).Exp {
-			case "AND":
-				node := Node{
-					Exp: buffer[pos].(Node).Exp,
-					Args: []interface{}{
-						bufferNew[len(bufferNew)-1],
-						buffer[pos+1],
-					},
-				}
-				bufferNew[len(bufferNew)-1] = node
-				pos += 2
-			default:
-				bufferNew = append(bufferNew, buffer[pos])
-				pos++
-			}
-		default:
-			bufferNew = append(bufferNew, buffer[pos])
-			pos++
+	p.errs = append(p.errs, &SyntaxError{
+		Code:    CodeUnknownIdentifier,
+		Message: fmt.Sprintf("the identifier '%s' was not found", name),
+		Token:   name,
+		Span:    span,
+	})
+
+	return
+}
+
+// stringNode returns a string literal with the parameters and variables in it replaced by their values, as
+// PowerCenter expands them in the text of an expression
+// Variables starting with $ are substituted straight away, while declared parameters are read when the expression is
+// evaluated, so 'a $$X b' is 'a ' || $$X || ' b' and gives the same result from Evaluate and from a compiled Program
+func (p *parser) stringNode(value string, span Span) (node Node) {
+	for _, v := range p.vars {
+		if strings.HasPrefix(v.N, "$") {
+			value = strings.ReplaceAll(value, v.N, v.V)
 		}
 	}
 
-	// "OR"
-	buffer = nil
-	buffer = append(buffer, bufferNew...)
-	bufferNew = nil
-	pos = 0
-	for pos < len(buffer) {
-		switch buffer[pos].(type) {
-		case Node:
-			switch buffer[pos].(Node).Exp {
-			case "OR":
-				node := Node{
-					Exp: buffer[pos].(Node).Exp,
-					Args: []interface{}{
-						bufferNew[len(bufferNew)-1],
-						buffer[pos+1],
-					},
-				}
-				bufferNew[len(bufferNew)-1] = node
-				pos += 2
-			default:
-				bufferNew = append(bufferNew, buffer[pos])
-				pos++
+	text := func(s string) Node { return Node{Exp: "VALUE", Value: NewString(s), Span: span} }
+	var parts []Node
+	for {
+		// the first declared parameter in the rest of the string, the longest one when names share a prefix
+		start, name := -1, ""
+		for _, d := range p.decls {
+			if !strings.HasPrefix(d.Name, "$") {
+				continue
 			}
-		default:
-			bufferNew = append(bufferNew, buffer[pos])
-			pos++
+			if i := strings.Index(value, d.Name); i >= 0 && (start < 0 || i < start || i == start && len(d.Name) > len(name)) {
+				start, name = i, d.Name
+			}
+		}
+		if start < 0 {
+			break
 		}
+		param, _ := p.variableNode(name, span)
+		parts = append(parts, text(value[:start]), param)
+		value = value[start+len(name):]
 	}
 
-	// validate each output is a Node
-	// TODO this check shouldn't be needed but keeping for now while in heavy dev
-	for _, b := range bufferNew {
-		if n, ok := b.(Node); ok {
-			output = append(output, n)
-		} else {
-			err = fmt.Errorf("exptected a Node but got: %s\n+%v", reflect.TypeOf(bufferNew[0]), bufferNew[0])
-			return
+	// the text before each parameter keeps the result a string, even when the parameter isn't one
+	node = text(value)
+	if len(parts) > 0 {
+		node = parts[0]
+		for _, part := range append(parts[1:], text(value)) {
+			node = Node{Exp: "||", Args: []Node{node, part}, Span: span}
 		}
 	}
 
 	return
 }
 
-// reformatFloat takes a number and rewrites it as a float (e.g. 2 => 2.000000) in string format
-func reformatFloat(i string) (o string, err error) {
-	f, err := strconv.ParseFloat(i, 64)
-	if err != nil {
-		return
+// unaryNode applies a unary operator to the operand
+// Negative numbers are folded into a single value (e.g. -1) and unary plus is dropped since it does nothing
+func unaryNode(op string, operand Node) Node {
+	switch op {
+	case "+":
+		return operand
+	case "-":
+		if operand.Exp == "VALUE" && operand.Value.typ.IsNumeric() && !operand.Value.IsNull() {
+			if v, err := negate(operand.Value); err == nil {
+				return Node{Exp: "VALUE", Value: v, Span: operand.Span}
+			}
+		}
 	}
-	o = fmt.Sprintf("%f", f)
 
-	return
+	return Node{Exp: op, Args: []Node{operand}, Span: operand.Span}
 }
 
 // Check if the identifier is a Literal
 func isLiteral(ident string) bool {
-	for _, l := range Literals {
+	for _, l := range literals {
 		if l == ident {
 			return true
 		}
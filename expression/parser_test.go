@@ -51,25 +51,22 @@ func TestParse(t *testing.T) {
 			input: `ABS(1)`,
 			expect: Node{
 				Exp: "ABS",
-				Args: []interface{}{
-					Node{"NUMBER", []interface{}{"1.000000"}},
+				Args: []Node{
+					integer(1),
 				},
 			},
 		},
 		{
-			input: `1`,
-			expect: Node{
-				Exp:  "NUMBER",
-				Args: []interface{}{"1.000000"},
-			},
+			input:  `1`,
+			expect: integer(1),
 		},
 		{
 			input: `1 + 2`,
 			expect: Node{
 				Exp: "+",
-				Args: []interface{}{
-					Node{"NUMBER", []interface{}{"1.000000"}},
-					Node{"NUMBER", []interface{}{"2.000000"}},
+				Args: []Node{
+					integer(1),
+					integer(2),
 				},
 			},
 		},
@@ -77,15 +74,15 @@ func TestParse(t *testing.T) {
 			input: `1 + 2 + 3`,
 			expect: Node{
 				Exp: "+",
-				Args: []interface{}{
+				Args: []Node{
 					Node{
 						Exp: "+",
-						Args: []interface{}{
-							Node{"NUMBER", []interface{}{"1.000000"}},
-							Node{"NUMBER", []interface{}{"2.000000"}},
+						Args: []Node{
+							integer(1),
+							integer(2),
 						},
 					},
-					Node{"NUMBER", []interface{}{"3.000000"}},
+					integer(3),
 				},
 			},
 		},
@@ -93,13 +90,13 @@ func TestParse(t *testing.T) {
 			input: `1 + 2 * 3`,
 			expect: Node{
 				Exp: "+",
-				Args: []interface{}{
-					Node{"NUMBER", []interface{}{"1.000000"}},
+				Args: []Node{
+					integer(1),
 					Node{
 						Exp: "*",
-						Args: []interface{}{
-							Node{"NUMBER", []interface{}{"2.000000"}},
-							Node{"NUMBER", []interface{}{"3.000000"}},
+						Args: []Node{
+							integer(2),
+							integer(3),
 						},
 					},
 				},
@@ -109,19 +106,19 @@ func TestParse(t *testing.T) {
 			input: `8 + (5 - 2) * 8`,
 			expect: Node{
 				Exp: "+",
-				Args: []interface{}{
-					Node{"NUMBER", []interface{}{"8.000000"}},
+				Args: []Node{
+					integer(8),
 					Node{
 						Exp: "*",
-						Args: []interface{}{
+						Args: []Node{
 							Node{
 								Exp: "-",
-								Args: []interface{}{
-									Node{"NUMBER", []interface{}{"5.000000"}},
-									Node{"NUMBER", []interface{}{"2.000000"}},
+								Args: []Node{
+									integer(5),
+									integer(2),
 								},
 							},
-							Node{"NUMBER", []interface{}{"8.000000"}},
+							integer(8),
 						},
 					},
 				},
@@ -131,12 +128,12 @@ func TestParse(t *testing.T) {
 			input: `1 + ABS(-1)`,
 			expect: Node{
 				Exp: "+",
-				Args: []interface{}{
-					Node{"NUMBER", []interface{}{"1.000000"}},
+				Args: []Node{
+					integer(1),
 					Node{
 						Exp: "ABS",
-						Args: []interface{}{
-							Node{"NUMBER", []interface{}{"-1.000000"}},
+						Args: []Node{
+							integer(-1),
 						},
 					},
 				},
@@ -146,9 +143,9 @@ func TestParse(t *testing.T) {
 			input: `RTRIM(' a ', ' ')`,
 			expect: Node{
 				Exp: "RTRIM",
-				Args: []interface{}{
-					Node{"STRING", []interface{}{` a `}},
-					Node{"STRING", []interface{}{` `}},
+				Args: []Node{
+					str(` a `),
+					str(` `),
 				},
 			},
 		},
@@ -156,21 +153,21 @@ func TestParse(t *testing.T) {
 			input: `IIF(1 + 2, ABS(5), 'b')`,
 			expect: Node{
 				Exp: "IIF",
-				Args: []interface{}{
+				Args: []Node{
 					Node{
 						Exp: "+",
-						Args: []interface{}{
-							Node{"NUMBER", []interface{}{"1.000000"}},
-							Node{"NUMBER", []interface{}{"2.000000"}},
+						Args: []Node{
+							integer(1),
+							integer(2),
 						},
 					},
 					Node{
 						Exp: "ABS",
-						Args: []interface{}{
-							Node{"NUMBER", []interface{}{"5.000000"}},
+						Args: []Node{
+							integer(5),
 						},
 					},
-					Node{"STRING", []interface{}{"b"}},
+					str("b"),
 				},
 			},
 		},
@@ -184,12 +181,156 @@ func TestParse(t *testing.T) {
 			t.Error(err)
 		}
 
-		if !reflect.DeepEqual(tc.expect, node) {
+		if !reflect.DeepEqual(tc.expect, clearSpans(node)) {
 			t.Errorf("Unexpected output\nExpected: \n%v\nGot: \n%v", tc.expect, node)
 		}
 	}
 }
 
+func TestParseUnary(t *testing.T) {
+	testCases := []struct {
+		input  string
+		expect Node
+	}{
+		{
+			input:  `-1`,
+			expect: integer(-1),
+		},
+		{
+			input:  `+1`,
+			expect: integer(1),
+		},
+		{
+			input: `5-3`,
+			expect: Node{
+				Exp:  "-",
+				Args: []Node{integer(5), integer(3)},
+			},
+		},
+		{
+			input: `a-1`,
+			expect: Node{
+				Exp:  "-",
+				Args: []Node{integer(2), integer(1)},
+			},
+		},
+		{
+			input: `1 - -2`,
+			expect: Node{
+				Exp:  "-",
+				Args: []Node{integer(1), integer(-2)},
+			},
+		},
+		{
+			input: `-ABS(a)`,
+			expect: Node{
+				Exp: "-",
+				Args: []Node{
+					{Exp: "ABS", Args: []Node{integer(2)}},
+				},
+			},
+		},
+		{
+			input: `- (a + b)`,
+			expect: Node{
+				Exp: "-",
+				Args: []Node{
+					{Exp: "+", Args: []Node{integer(2), integer(3)}},
+				},
+			},
+		},
+		{
+			input: `-ABS(a) * 2`,
+			expect: Node{
+				Exp: "*",
+				Args: []Node{
+					{Exp: "-", Args: []Node{{Exp: "ABS", Args: []Node{integer(2)}}}},
+					integer(2),
+				},
+			},
+		},
+		{
+			input: `NOT ISNULL(a)`,
+			expect: Node{
+				Exp: "NOT",
+				Args: []Node{
+					{Exp: "ISNULL", Args: []Node{integer(2)}},
+				},
+			},
+		},
+		{
+			input: `NOT NOT a`,
+			expect: Node{
+				Exp: "NOT",
+				Args: []Node{
+					{Exp: "NOT", Args: []Node{integer(2)}},
+				},
+			},
+		},
+		{
+			input: `NOT a AND b`,
+			expect: Node{
+				Exp: "AND",
+				Args: []Node{
+					{Exp: "NOT", Args: []Node{integer(2)}},
+					integer(3),
+				},
+			},
+		},
+		{
+			input: `NOT a = b`,
+			expect: Node{
+				Exp: "=",
+				Args: []Node{
+					{Exp: "NOT", Args: []Node{integer(2)}},
+					integer(3),
+				},
+			},
+		},
+		{
+			input: `NOT a * b`,
+			expect: Node{
+				Exp: "*",
+				Args: []Node{
+					{Exp: "NOT", Args: []Node{integer(2)}},
+					integer(3),
+				},
+			},
+		},
+		{
+			input: `- NOT a`,
+			expect: Node{
+				Exp: "-",
+				Args: []Node{
+					{Exp: "NOT", Args: []Node{integer(2)}},
+				},
+			},
+		},
+	}
+
+	vars := []Variable{
+		{"a", "INTEGER", "2"},
+		{"b", "INTEGER", "3"},
+	}
+
+	for _, tc := range testCases {
+		node, err := parse([]byte(tc.input), vars)
+		if err != nil {
+			t.Error(err)
+		}
+
+		if !reflect.DeepEqual(tc.expect, clearSpans(node)) {
+			t.Errorf("Unexpected output for %s\nExpected: \n%v\nGot: \n%v", tc.input, tc.expect, node)
+		}
+	}
+
+	for _, input := range []string{`1 -`, `NOT`, `1 * - `} {
+		if _, err := parse([]byte(input), vars); err == nil {
+			t.Errorf("Expected an error for %s", input)
+		}
+	}
+}
+
 func TestParseVars(t *testing.T) {
 	testCases := []struct {
 		input  string
@@ -203,20 +344,20 @@ func TestParseVars(t *testing.T) {
 			},
 			expect: Node{
 				Exp: "SUBSTR",
-				Args: []interface{}{
+				Args: []Node{
 					Node{
 						Exp: "LTRIM",
-						Args: []interface{}{
+						Args: []Node{
 							Node{
 								Exp: "RTRIM",
-								Args: []interface{}{
-									Node{"NUMBER", []interface{}{"2.000000"}},
+								Args: []Node{
+									integer(2),
 								},
 							},
 						},
 					},
-					Node{"NUMBER", []interface{}{"1.000000"}},
-					Node{"NUMBER", []interface{}{"7.000000"}},
+					integer(1),
+					integer(7),
 				},
 			},
 		},
@@ -228,8 +369,107 @@ func TestParseVars(t *testing.T) {
 			t.Error(err)
 		}
 
-		if !reflect.DeepEqual(tc.expect, node) {
+		if !reflect.DeepEqual(tc.expect, clearSpans(node)) {
 			t.Errorf("Unexpected output\nExpected: \n%v\nGot: \n%v", tc.expect, node)
 		}
 	}
 }
+
+// clearSpans removes the positions from the node and its args so that only the structure is compared
+func clearSpans(node Node) Node {
+	node.Span = Span{}
+	if node.Args != nil {
+		args := make([]Node, len(node.Args))
+		for i, arg := range node.Args {
+			args[i] = clearSpans(arg)
+		}
+		node.Args = args
+	}
+
+	return node
+}
+
+// integer is a shorthand for a node holding an integer value
+func integer(i int32) Node {
+	return Node{Exp: "VALUE", Value: NewInteger(i)}
+}
+
+// str is a shorthand for a node holding a string value
+func str(s string) Node {
+	return Node{Exp: "VALUE", Value: NewString(s)}
+}
+
+func TestParsePositions(t *testing.T) {
+	input := "IIF(a > 1,\n    ABS(-a),\n    'x')"
+	vars := []Variable{
+		{"a", "INTEGER", "2"},
+	}
+
+	node, err := parse([]byte(input), vars)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		node   Node
+		expect Span
+	}{
+		{node, Span{Position{1, 1}, Position{3, 8}}},
+		{node.Args[0], Span{Position{1, 5}, Position{1, 9}}},
+		{node.Args[0].Args[0], Span{Position{1, 5}, Position{1, 5}}},
+		{node.Args[1], Span{Position{2, 5}, Position{2, 11}}},
+		{node.Args[1].Args[0], Span{Position{2, 9}, Position{2, 10}}},
+		{node.Args[2], Span{Position{3, 5}, Position{3, 7}}},
+	}
+
+	for _, tc := range testCases {
+		if tc.node.Span != tc.expect {
+			t.Errorf("For %s, expected span %v but got %v", tc.node.Exp, tc.expect, tc.node.Span)
+		}
+	}
+}
+
+func TestSyntaxErrors(t *testing.T) {
+	testCases := []struct {
+		input  string
+		line   int
+		column int
+		token  string
+	}{
+		{`ABS(1 2)`, 1, 7, `2`},
+		{`ABS(1`, 1, 5, ``},
+		{`ABS(1))`, 1, 7, `)`},
+		{`(1 + 2`, 1, 6, ``},
+		{`* 2`, 1, 1, `*`},
+		{`1 +`, 1, 3, ``},
+		{`ABS(1,)`, 1, 7, `)`},
+		{`ABS(, 1)`, 1, 5, `,`},
+		{`1 2`, 1, 3, `2`},
+		{"IIF(a > 1,\n  b c,\n  0)", 2, 5, `c`},
+		{`NOPE(1)`, 1, 1, `NOPE`},
+		{`ABS`, 1, 1, `ABS`},
+		{`a + missing`, 1, 5, `missing`},
+		{`1 + #`, 1, 5, `#`},
+		{"1 +\n 'abc", 2, 2, `'abc`},
+	}
+
+	vars := []Variable{
+		{"a", "INTEGER", "2"},
+		{"b", "INTEGER", "3"},
+		{"c", "INTEGER", "4"},
+	}
+
+	for _, tc := range testCases {
+		_, err := parse([]byte(tc.input), vars)
+		sErr, ok := err.(*SyntaxError)
+		if !ok {
+			t.Errorf("Input: %s\nExpected a SyntaxError but got %T: %v", tc.input, err, err)
+			continue
+		}
+
+		if sErr.Span.Start.Line != tc.line || sErr.Span.Start.Column != tc.column || sErr.Token != tc.token {
+			t.Errorf("Input: %s\nExpected line %d, column %d, token `%s`, got line %d, column %d, token `%s`\n%v",
+				tc.input, tc.line, tc.column, tc.token, sErr.Span.Start.Line, sErr.Span.Start.Column, sErr.Token, sErr)
+		}
+	}
+}
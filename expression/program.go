@@ -0,0 +1,40 @@
+// programs are expressions which are lexed and parsed once and then evaluated for many rows
+
+package expression
+
+// Row holds the values of the ports for one row, keyed by port name
+type Row map[string]Value
+
+// Program is a compiled expression which can be run for many rows
+// A Program is never modified after it is compiled so it can be run from many goroutines at once
+type Program struct {
+	input     string
+	node      Node
+	schema    []Declaration
+	evaluator *Evaluator
+}
+
+// Compile lexes and parses the input once, resolving identifiers against the ports and parameters in the schema
+// The first problem found is returned as a *SyntaxError; use Validate to get all of them
+// The program uses an Evaluator with the default Options
+func Compile(input string, schema []Declaration) (program *Program, err error) {
+	return defaultEvaluator.Compile(input, schema)
+}
+
+// String returns the expression the program was compiled from
+func (p *Program) String() string {
+	return p.input
+}
+
+// Schema returns the ports and parameters the program was compiled with
+func (p *Program) Schema() []Declaration {
+	return append([]Declaration(nil), p.schema...)
+}
+
+// Run evaluates the program with the values of the ports in the row
+// A port which isn't in the row, or holds an untyped NULL, is a NULL of its declared type
+func (p *Program) Run(row Row) (result Value, err error) {
+	result, err = evaluateNode(p.node, p.evaluator.newEnvironment(row))
+
+	return
+}
@@ -0,0 +1,173 @@
+package expression
+
+import (
+	"fmt"
+	"testing"
+)
+
+var programSchema = []Declaration{
+	{Name: "in_NAME", Type: TypeString},
+	{Name: "in_QTY", Type: TypeInteger},
+	{Name: "in_PRICE", Type: TypeDecimal},
+	{Name: "$$DISCOUNT", Type: TypeDouble},
+}
+
+func TestProgram(t *testing.T) {
+	program, err := Compile("IIF(ISNULL(in_QTY), 'none', CONCAT(in_NAME, in_QTY * in_PRICE))", programSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		row    Row
+		expect string
+	}{
+		{Row{"in_NAME": NewString("a"), "in_QTY": NewInteger(2), "in_PRICE": NewDecimal(1.5, 2, 1)}, "a3"},
+		{Row{"in_NAME": NewString("b"), "in_QTY": NewInteger(3), "in_PRICE": NewDecimal(0.25, 3, 2)}, "b0.75"},
+		{Row{"in_NAME": NewString("c")}, "none"},
+		{Row{"in_NAME": NewString("d"), "in_QTY": Value{}}, "none"},
+		{Row{"in_QTY": NewInteger(1), "in_PRICE": NewDecimal(2, 1, 0)}, "2"},
+	}
+
+	for _, tc := range testCases {
+		result, err := program.Run(tc.row)
+		if err != nil {
+			t.Errorf("Row: %v\nUnexpected error: %v", tc.row, err)
+			continue
+		}
+		if result.String() != tc.expect {
+			t.Errorf("Row: %v\nExpected: `%s`, got `%s`", tc.row, tc.expect, result.String())
+		}
+	}
+}
+
+func TestProgramTypes(t *testing.T) {
+	// a missing port is a NULL of its declared type, so the type of the result is known
+	program, err := Compile("ABS(in_QTY)", programSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := program.Run(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.IsNull() || result.Type() != TypeInteger {
+		t.Errorf("Expected: `NULL INTEGER`, got `%s %s`", result, result.Type())
+	}
+}
+
+func TestParametersInStrings(t *testing.T) {
+	schema := []Declaration{{Name: "$$X", Type: TypeString}, {Name: "$$XY", Type: TypeInteger}}
+	testCases := []struct {
+		input  string
+		vars   []Variable
+		row    Row
+		expect string
+	}{
+		{`'a $$X b'`, []Variable{{N: "$$X", T: "STRING", V: "zz"}}, Row{"$$X": NewString("zz")}, `a zz b`},
+		{`'$$X$$X'`, []Variable{{N: "$$X", T: "STRING", V: "z"}}, Row{"$$X": NewString("z")}, `zz`},
+		{`'n=$$XY'`, []Variable{{N: "$$XY", T: "INTEGER", V: "12"}}, Row{"$$XY": NewInteger(12)}, `n=12`},
+		{`'no parameters'`, nil, nil, `no parameters`},
+	}
+
+	// a parameter in a string is replaced by its value whether it is passed to Evaluate or read from the row
+	for _, tc := range testCases {
+		result, err := Evaluate(tc.input, tc.vars)
+		if err != nil || result.String() != tc.expect {
+			t.Errorf("Input: %s\nEvaluate: Expected: `%s`, got `%s` (%v)", tc.input, tc.expect, result, err)
+		}
+
+		program, err := Compile(tc.input, schema)
+		if err != nil {
+			t.Errorf("Input: %s\nUnexpected error: %v", tc.input, err)
+			continue
+		}
+		if result, err = program.Run(tc.row); err != nil || result.String() != tc.expect {
+			t.Errorf("Input: %s\nRun: Expected: `%s`, got `%s` (%v)", tc.input, tc.expect, result, err)
+		}
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	testCases := []struct {
+		input  string
+		expect string
+	}{
+		{"in_MISSING + 1", "syntax error at line 1, column 1: the identifier 'in_MISSING' was not found"},
+		{"ABS(in_NAME, 1)", "syntax error at line 1, column 1: ABS expects 1 argument, got 2"},
+		{"in_QTY +", "syntax error at line 1, column 8: reached the end of the expression"},
+	}
+
+	for _, tc := range testCases {
+		_, err := Compile(tc.input, programSchema)
+		if err == nil || err.Error() != tc.expect {
+			t.Errorf("Input: %s\nExpected: `%s`, got `%v`", tc.input, tc.expect, err)
+		}
+	}
+}
+
+func TestProgramRowErrors(t *testing.T) {
+	program, err := Compile("in_PRICE / in_QTY", programSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = program.Run(Row{"in_QTY": NewInteger(0), "in_PRICE": NewDecimal(1, 1, 0)})
+	if _, ok := err.(*RowError); !ok {
+		t.Errorf("Expected a *RowError, got `%v`", err)
+	}
+
+	// the error is for that row only
+	result, err := program.Run(Row{"in_QTY": NewInteger(4), "in_PRICE": NewDecimal(1, 1, 0)})
+	if err != nil || result.String() != "0.25" {
+		t.Errorf("Expected: `0.25`, got `%s` (%v)", result, err)
+	}
+}
+
+const benchmarkExpression = "CONCAT(IIF(in_QTY > 10, in_QTY * in_PRICE * (1 - $$DISCOUNT), in_QTY * in_PRICE), LTRIM(in_NAME))"
+
+func BenchmarkProgramRun(b *testing.B) {
+	program, err := Compile(benchmarkExpression, programSchema)
+	if err != nil {
+		b.Fatal(err)
+	}
+	rows := make([]Row, 100)
+	for i := range rows {
+		rows[i] = Row{
+			"in_NAME":    NewString(fmt.Sprintf("  item %d", i)),
+			"in_QTY":     NewInteger(int32(i)),
+			"in_PRICE":   NewDecimal(float64(i)/4, 5, 2),
+			"$$DISCOUNT": NewDouble(0.1),
+		}
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := program.Run(rows[i%len(rows)]); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkCompile(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		if _, err := Compile(benchmarkExpression, programSchema); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkEvaluate(b *testing.B) {
+	vars := []Variable{
+		{N: "in_NAME", T: "STRING", V: "  item"},
+		{N: "in_QTY", T: "INTEGER", V: "20"},
+		{N: "in_PRICE", T: "DECIMAL", V: "2.50"},
+		{N: "$$DISCOUNT", T: "DOUBLE", V: "0.1"},
+	}
+
+	for i := 0; i < b.N; i++ {
+		if _, err := Evaluate(benchmarkExpression, vars); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
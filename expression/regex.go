@@ -0,0 +1,365 @@
+// regular expression functions
+// PowerCenter uses Perl compatible regular expressions while Go's regexp package implements RE2, which has most of
+// the same syntax but deliberately leaves out the constructs that need backtracking
+// translatePattern converts what it can and rejects the rest with an error naming the construct:
+//   - named groups (?<name>...) and (?'name'...) become (?P<name>...)
+//   - comments (?#...) are removed
+//   - \h and \H become [\t ] and [^\t ], \e becomes \x1B and \Z becomes (?:\n?\z) since it also matches before a final
+//     newline
+//   - backreferences (\1, \k<name>, (?P=name)), lookaround ((?=, (?!, (?<=, (?<!), atomic groups (?>...), possessive
+//     quantifiers (a++), conditionals (?(...)), recursion (?R), \G, \K and the x flag aren't supported
+
+package expression
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// translatePattern converts a Perl compatible regular expression to RE2 syntax
+func translatePattern(pattern string) (translated string, err error) {
+	var b strings.Builder
+	inClass := false // inside [...], where brackets and most escapes mean something else
+
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		rest := pattern[i:]
+
+		switch {
+		case c == '\\':
+			if i+1 >= len(pattern) {
+				err = fmt.Errorf("the pattern ends with a \\")
+				return
+			}
+			next := pattern[i+1]
+			switch {
+			case next >= '1' && next <= '9' && !inClass:
+				err = fmt.Errorf("backreferences such as \\%c aren't supported", next)
+				return
+			case (next == 'k' || next == 'g') && !inClass:
+				err = fmt.Errorf("backreferences such as \\%c aren't supported", next)
+				return
+			case next == 'G' || next == 'K':
+				err = fmt.Errorf("\\%c isn't supported", next)
+				return
+			case next == 'Q':
+				// quoted text is the same in both, copy it up to \E so that nothing in it is translated
+				end := strings.Index(pattern[i:], `\E`)
+				if end < 0 {
+					end = len(pattern) - i
+				} else {
+					end += 2
+				}
+				b.WriteString(pattern[i : i+end])
+				i += end - 1
+				continue
+			case next == 'h':
+				b.WriteString(classOrSet(inClass, `\t `))
+			case next == 'H' && !inClass:
+				b.WriteString(`[^\t ]`)
+			case next == 'e':
+				b.WriteString(`\x1B`)
+			case next == 'Z' && !inClass:
+				b.WriteString(`(?:\n?\z)`)
+			default:
+				b.WriteByte(c)
+				b.WriteByte(next)
+			}
+			i++
+		case inClass:
+			if c == ']' {
+				inClass = false
+			}
+			b.WriteByte(c)
+		case c == '[':
+			inClass = true
+			b.WriteByte(c)
+			// a ] straight after [ or [^ is a literal
+			if strings.HasPrefix(rest, "[^]") {
+				b.WriteString("^]")
+				i += 2
+			} else if strings.HasPrefix(rest, "[]") {
+				b.WriteByte(']')
+				i++
+			} else if strings.HasPrefix(rest, "[[:") {
+				// POSIX classes such as [[:alpha:]] are the same in both
+				if end := strings.Index(rest, ":]"); end > 0 {
+					b.WriteString(rest[1 : end+2])
+					i += end + 1
+				}
+			}
+		case strings.HasPrefix(rest, "(?#"):
+			end := strings.IndexByte(rest, ')')
+			if end < 0 {
+				err = fmt.Errorf("the comment (?# isn't closed")
+				return
+			}
+			i += end
+		case strings.HasPrefix(rest, "(?<") && !strings.HasPrefix(rest, "(?<=") && !strings.HasPrefix(rest, "(?<!"),
+			strings.HasPrefix(rest, "(?'"):
+			b.WriteString("(?P<")
+			i += 2
+			if rest[2] == '\'' {
+				end := strings.IndexByte(rest[3:], '\'')
+				if end < 0 {
+					err = fmt.Errorf("the name of the group at %d isn't closed", i-1)
+					return
+				}
+				b.WriteString(rest[3:3+end] + ">")
+				i += end + 1
+			}
+		case strings.HasPrefix(rest, "(?="), strings.HasPrefix(rest, "(?!"), strings.HasPrefix(rest, "(?<="),
+			strings.HasPrefix(rest, "(?<!"):
+			n := 3
+			if rest[2] == '<' {
+				n = 4
+			}
+			err = fmt.Errorf("lookaround such as %s isn't supported", rest[:n])
+			return
+		case strings.HasPrefix(rest, "(?>"):
+			err = fmt.Errorf("atomic groups (?> aren't supported")
+			return
+		case strings.HasPrefix(rest, "(?P="):
+			err = fmt.Errorf("backreferences such as (?P= aren't supported")
+			return
+		case strings.HasPrefix(rest, "(?("):
+			err = fmt.Errorf("conditionals (?( aren't supported")
+			return
+		case strings.HasPrefix(rest, "(?R"), strings.HasPrefix(rest, "(?&"), isRecursion(rest):
+			err = fmt.Errorf("recursion such as %s isn't supported", rest[:strings.IndexByte(rest, ')')+1])
+			return
+		case strings.HasPrefix(rest, "(?") && hasExtendedFlag(rest):
+			err = fmt.Errorf("the x flag isn't supported")
+			return
+		case c == '+' && i > 0 && strings.ContainsRune("*+?}", rune(pattern[i-1])) && !escaped(pattern, i-1):
+			err = fmt.Errorf("possessive quantifiers such as %s aren't supported", pattern[i-1:i+1])
+			return
+		default:
+			b.WriteByte(c)
+		}
+	}
+
+	translated = b.String()
+
+	return
+}
+
+// classOrSet returns the characters as they are written inside a class, or as a class of their own
+func classOrSet(inClass bool, chars string) string {
+	if inClass {
+		return chars
+	}
+
+	return "[" + chars + "]"
+}
+
+// isRecursion checks for a call to a numbered group, e.g. (?1) or (?-1)
+func isRecursion(s string) bool {
+	end := strings.IndexByte(s, ')')
+	if !strings.HasPrefix(s, "(?") || end < 3 {
+		return false
+	}
+	_, err := strconv.Atoi(s[2:end])
+
+	return err == nil
+}
+
+// hasExtendedFlag checks if a group of flags, e.g. (?ix) or (?x:...), turns on the x flag
+func hasExtendedFlag(s string) bool {
+	for _, c := range s[2:] {
+		switch {
+		case c == 'x':
+			return true
+		case c == ':' || c == ')' || c == '-':
+			return false
+		case c < 'a' || c > 'z':
+			return false
+		}
+	}
+
+	return false
+}
+
+// escaped checks if the character at i is escaped by an odd number of backslashes
+func escaped(s string, i int) bool {
+	n := 0
+	for j := i - 1; j >= 0 && s[j] == '\\'; j-- {
+		n++
+	}
+
+	return n%2 == 1
+}
+
+// compiledPatterns caches the translated and compiled patterns, which are almost always literals, so they aren't
+// compiled for every row; it is emptied when it gets too big rather than grow without a bound
+var compiledPatterns = struct {
+	sync.Mutex
+	m map[string]*regexp.Regexp
+}{m: map[string]*regexp.Regexp{}}
+
+const maxCompiledPatterns = 256
+
+// compilePattern translates and compiles a pattern for a function, returning a row error for an invalid one
+// When whole is true the pattern has to match all of the subject
+func compilePattern(fn string, pattern string, whole bool) (re *regexp.Regexp, err error) {
+	key := pattern
+	if whole {
+		key = "\x00" + pattern
+	}
+	compiledPatterns.Lock()
+	re, found := compiledPatterns.m[key]
+	compiledPatterns.Unlock()
+	if found {
+		return
+	}
+
+	translated, err := translatePattern(pattern)
+	if err == nil {
+		if whole {
+			translated = `\A(?:` + translated + `)\z`
+		}
+		re, err = regexp.Compile(translated)
+	}
+	if err != nil {
+		err = &RowError{Function: fn, Message: fmt.Sprintf("invalid pattern '%s': %v", pattern, err)}
+		return
+	}
+
+	compiledPatterns.Lock()
+	if len(compiledPatterns.m) >= maxCompiledPatterns {
+		compiledPatterns.m = map[string]*regexp.Regexp{}
+	}
+	compiledPatterns.m[key] = re
+	compiledPatterns.Unlock()
+
+	return
+}
+
+// regExtract returns the subpattern subPatternNum (1 by default, 0 for the whole match) of the first match, or NULL
+// when the pattern doesn't match or the subpattern didn't take part in the match
+// With match_from_start the match must begin at the start of the subject
+func regExtract(args ...Value) (result Value, err error) {
+	result = NewNull(TypeString)
+	if args[0].IsNull() || args[1].IsNull() {
+		return
+	}
+
+	re, err := compilePattern("REG_EXTRACT", toText(args[1]), false)
+	if err != nil {
+		return
+	}
+	subPattern := 1
+	if len(args) > 2 && !args[2].IsNull() {
+		if subPattern, err = toPosition("REG_EXTRACT", args[2]); err != nil {
+			return
+		}
+	}
+	if subPattern < 0 || subPattern > re.NumSubexp() {
+		err = &RowError{
+			Function: "REG_EXTRACT",
+			Message:  fmt.Sprintf("subPatternNum %d is not between 0 and %d", subPattern, re.NumSubexp()),
+		}
+		return
+	}
+	fromStart := false
+	if len(args) > 3 && !args[3].IsNull() {
+		var flag Value
+		if flag, err = toNumeric("REG_EXTRACT", args[3]); err != nil {
+			return
+		}
+		fromStart = flag.Float() != 0
+	}
+
+	subject := toText(args[0])
+	match := re.FindStringSubmatchIndex(subject)
+	if match == nil || fromStart && match[0] != 0 || match[2*subPattern] < 0 {
+		return
+	}
+	result = NewString(subject[match[2*subPattern]:match[2*subPattern+1]])
+
+	return
+}
+
+// regMatch returns TRUE if the pattern matches the whole subject
+func regMatch(args ...Value) (result Value, err error) {
+	if anyNull(args) {
+		result = NewNull(TypeInteger)
+		return
+	}
+
+	re, err := compilePattern("REG_MATCH", toText(args[1]), true)
+	if err != nil {
+		return
+	}
+	result = boolValue(re.MatchString(toText(args[0])))
+
+	return
+}
+
+// regReplace replaces the matches of the pattern with replace, where $1 or \1 (up to 9) is the text of a subpattern
+// and $0 the whole match; numReplacements limits how many of the first matches are replaced, all of them when it is
+// omitted, NULL or not positive
+func regReplace(args ...Value) (result Value, err error) {
+	if anyNull(args[:3]) {
+		result = NewNull(TypeString)
+		return
+	}
+
+	re, err := compilePattern("REG_REPLACE", toText(args[1]), false)
+	if err != nil {
+		return
+	}
+	limit := -1
+	if len(args) > 3 && !args[3].IsNull() {
+		if limit, err = toPosition("REG_REPLACE", args[3]); err != nil {
+			return
+		}
+		if limit <= 0 {
+			limit = -1
+		}
+	}
+
+	subject, replace := toText(args[0]), toText(args[2])
+	var b strings.Builder
+	last := 0
+	for _, match := range re.FindAllStringSubmatchIndex(subject, limit) {
+		b.WriteString(subject[last:match[0]])
+		b.WriteString(expandReplacement(replace, subject, match))
+		last = match[1]
+	}
+	b.WriteString(subject[last:])
+	result = NewString(b.String())
+
+	return
+}
+
+// expandReplacement substitutes the subpatterns of a match for $n and \n in the replacement; a subpattern which
+// didn't take part in the match is empty and \\ or $$ is a literal \ or $
+func expandReplacement(replace string, subject string, match []int) string {
+	var b strings.Builder
+	for i := 0; i < len(replace); i++ {
+		c := replace[i]
+		if (c == '$' || c == '\\') && i+1 < len(replace) {
+			next := replace[i+1]
+			if next == c {
+				b.WriteByte(c)
+				i++
+				continue
+			}
+			if next >= '0' && next <= '9' {
+				n := int(next - '0')
+				if 2*n+1 < len(match) && match[2*n] >= 0 {
+					b.WriteString(subject[match[2*n]:match[2*n+1]])
+				}
+				i++
+				continue
+			}
+		}
+		b.WriteByte(c)
+	}
+
+	return b.String()
+}
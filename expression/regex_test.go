@@ -0,0 +1,118 @@
+package expression
+
+import (
+	"testing"
+)
+
+func TestREGEXTRACT(t *testing.T) {
+	runStringTests(t, []stringTestCase{
+		{`REG_EXTRACT('Stephen Graham Smith', '(\w+)\s+(\w+)\s+(\w+)', 2)`, `Graham`},
+		{`REG_EXTRACT('Stephen Graham Smith', '(\w+)\s+(\w+)\s+(\w+)')`, `Stephen`},
+		{`REG_EXTRACT('Stephen Graham Smith', '(\w+)\s+(\w+)', 0)`, `Stephen Graham`},
+		{`REG_EXTRACT('Stephen Smith', '(\w+)\s+(\w+ )?(\w+)', 2)`, `NULL`},
+		{`REG_EXTRACT('Stephen', '(\d+)')`, `NULL`},
+		{`REG_EXTRACT('id: 42', '(\d+)', 1, 1)`, `NULL`},
+		{`REG_EXTRACT('42 ids', '(\d+)', 1, 1)`, `42`},
+		{`REG_EXTRACT('2021-06-15', '(?<year>\d{4})-(?<month>\d\d)', 2)`, `06`},
+		{`REG_EXTRACT('a	b', 'a\h(b)')`, `b`},
+		{`REG_EXTRACT(NULL, '(a)')`, `NULL`},
+	})
+}
+
+func TestREGMATCH(t *testing.T) {
+	runStringTests(t, []stringTestCase{
+		{`REG_MATCH('408-555-1212', '(\d\d\d-\d\d\d-\d\d\d\d)')`, `1`},
+		{`REG_MATCH('(408) 555-1212', '(\d\d\d-\d\d\d-\d\d\d\d)')`, `0`},
+		{`REG_MATCH('tel 408-555-1212', '.*\d{3}-\d{4}')`, `1`},
+		{`REG_MATCH('abc', 'a|abc')`, `1`},
+		{`REG_MATCH('abc', '(?i)ABC(?# case-insensitive)')`, `1`},
+		{`REG_MATCH('a+b', '\Qa+b\E')`, `1`},
+		{`REG_MATCH('a]', '[]a]+')`, `1`},
+		{`REG_MATCH('x', '[[:alpha:]]')`, `1`},
+		{`REG_MATCH('abc', 'abc\Z')`, `1`},
+		{`REG_MATCH('abc' || CHR(10), 'abc\Z')`, `1`},
+		{`REG_MATCH('abc' || CHR(10), 'abc\z')`, `0`},
+		{`REG_MATCH('abc' || CHR(10) || CHR(10), 'abc\Z')`, `0`},
+		{`REG_MATCH(NULL, 'a')`, `NULL`},
+		{`REG_MATCH('a', NULL)`, `NULL`},
+		{
+			`REG_MATCH('abc', '(a)\1')`,
+			`<<Expression Error>> [REG_MATCH]: invalid pattern '(a)\1': backreferences such as \1 aren't supported`,
+		},
+		{
+			`REG_MATCH('abc', '(?<n>a)\k<n>')`,
+			`<<Expression Error>> [REG_MATCH]: invalid pattern '(?<n>a)\k<n>': backreferences such as \k aren't supported`,
+		},
+		{
+			`REG_MATCH('abc', '(?P<n>a)(?P=n)')`,
+			`<<Expression Error>> [REG_MATCH]: invalid pattern '(?P<n>a)(?P=n)': backreferences such as (?P= aren't supported`,
+		},
+		{
+			`REG_MATCH('abc', 'a(?=b)')`,
+			`<<Expression Error>> [REG_MATCH]: invalid pattern 'a(?=b)': lookaround such as (?= isn't supported`,
+		},
+		{
+			`REG_MATCH('abc', '(?<!a)b')`,
+			`<<Expression Error>> [REG_MATCH]: invalid pattern '(?<!a)b': lookaround such as (?<! isn't supported`,
+		},
+		{
+			`REG_MATCH('abc', '(?>a+)b')`,
+			`<<Expression Error>> [REG_MATCH]: invalid pattern '(?>a+)b': atomic groups (?> aren't supported`,
+		},
+		{
+			`REG_MATCH('abc', 'a++b')`,
+			`<<Expression Error>> [REG_MATCH]: invalid pattern 'a++b': possessive quantifiers such as ++ aren't supported`,
+		},
+		{
+			`REG_MATCH('abc', '\d{2}+')`,
+			`<<Expression Error>> [REG_MATCH]: invalid pattern '\d{2}+': possessive quantifiers such as }+ aren't supported`,
+		},
+		{
+			`REG_MATCH('abc', '(?(1)a|b)')`,
+			`<<Expression Error>> [REG_MATCH]: invalid pattern '(?(1)a|b)': conditionals (?( aren't supported`,
+		},
+		{
+			`REG_MATCH('abc', '(a|(?R))')`,
+			`<<Expression Error>> [REG_MATCH]: invalid pattern '(a|(?R))': recursion such as (?R) isn't supported`,
+		},
+		{
+			`REG_MATCH('abc', '(?x) a b')`,
+			`<<Expression Error>> [REG_MATCH]: invalid pattern '(?x) a b': the x flag isn't supported`,
+		},
+		{`REG_MATCH('abc', '\Gabc')`, `<<Expression Error>> [REG_MATCH]: invalid pattern '\Gabc': \G isn't supported`},
+		{
+			`REG_MATCH('abc', '(a')`,
+			"<<Expression Error>> [REG_MATCH]: invalid pattern '(a': error parsing regexp: missing closing ): `\\A(?:(a)\\z`",
+		},
+	})
+}
+
+func TestREGREPLACE(t *testing.T) {
+	runStringTests(t, []stringTestCase{
+		{`REG_REPLACE('Adam Smith', '\s+', '')`, `AdamSmith`},
+		{`REG_REPLACE('Grant  Hill', '\s+', ' ')`, `Grant Hill`},
+		{`REG_REPLACE('a.b.c.d', '\.', '-', 2)`, `a-b-c.d`},
+		{`REG_REPLACE('a.b.c.d', '\.', '-', 0)`, `a-b-c-d`},
+		{`REG_REPLACE('a.b.c.d', '\.', '-', NULL)`, `a-b-c-d`},
+		{`REG_REPLACE('Smith, John', '(\w+), (\w+)', '$2 $1')`, `John Smith`},
+		{`REG_REPLACE('Smith, John', '(\w+), (\w+)', '\2 \1')`, `John Smith`},
+		{`REG_REPLACE('5', '(\d)', '$$$1 \\ $0')`, `$5 \ 5`},
+		{`REG_REPLACE('abc', 'x*', '-')`, `-a-b-c-`},
+		{`REG_REPLACE(NULL, 'a', 'b')`, `NULL`},
+		{`REG_REPLACE('abc', 'a', NULL)`, `NULL`},
+	})
+}
+
+func TestTranslatePattern(t *testing.T) {
+	translated, err := translatePattern(`(?'year'\d{4})\e\Z`)
+	if expect := `(?P<year>\d{4})\x1B(?:\n?\z)`; err != nil || translated != expect {
+		t.Errorf("Expected: `%s`, got `%s` (%v)", expect, translated, err)
+	}
+
+	// these look like the unsupported constructs but aren't
+	for _, pattern := range []string{`a\++`, `[+]+`, `[\1]`, `(?i:a)`, `(?:a)+`} {
+		if _, err := translatePattern(pattern); err != nil {
+			t.Errorf("Pattern: %s\nUnexpected error: %v", pattern, err)
+		}
+	}
+}
@@ -0,0 +1,548 @@
+// signatures declare the arguments and return type of every Informatica function
+
+package expression
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Kind is the set of datatypes a parameter accepts
+type Kind int
+
+// Parameter kinds; they can be combined, e.g. KindDate | KindNumeric for ROUND
+const (
+	KindString Kind = 1 << iota
+	KindNumeric
+	KindDate
+	KindBinary
+	KindAny = KindString | KindNumeric | KindDate | KindBinary
+)
+
+func (k Kind) String() string {
+	if k == KindAny {
+		return "any value"
+	}
+
+	names := make([]string, 0)
+	for _, kind := range []struct {
+		k    Kind
+		name string
+	}{
+		{KindString, "a string"},
+		{KindNumeric, "a number"},
+		{KindDate, "a date"},
+		{KindBinary, "a binary"},
+	} {
+		if k&kind.k != 0 {
+			names = append(names, kind.name)
+		}
+	}
+
+	return strings.Join(names, " or ")
+}
+
+// Accepts checks if a value of the given type can be passed to a parameter of this kind
+// Strings and numbers are converted implicitly, so either can be passed where the other is expected
+func (k Kind) Accepts(t Type) bool {
+	switch t {
+	case TypeNull:
+		return true
+	case TypeString:
+		return k&(KindString|KindNumeric) != 0
+	case TypeInteger, TypeBigint, TypeDecimal, TypeDouble:
+		return k&(KindNumeric|KindString) != 0
+	case TypeDate:
+		return k&KindDate != 0
+	case TypeBinary:
+		return k&KindBinary != 0
+	}
+
+	return false
+}
+
+// Param is a function parameter
+type Param struct {
+	Name     string
+	Kind     Kind
+	Optional bool // may be omitted, along with any parameters after it
+	Variadic bool // the trailing variadic parameters repeat as a group any number of times
+}
+
+// Signature describes how a function is called
+type Signature struct {
+	Name       string
+	Params     []Param
+	Returns    Type // TypeNull when the return type isn't known until the function is evaluated
+	ReturnsArg int  // when > 0 the function returns the type of this argument (1 based) instead
+	Aggregate  bool // e.g. SUM, only valid in an Aggregator transformation
+	Window     bool // e.g. MOVINGAVG, depends on the rows before (or after) the current row
+	SideEffect bool // e.g. ABORT, SETVARIABLE, changes state outside of the expression
+}
+
+// MinArgs is the number of arguments which must be passed
+func (s Signature) MinArgs() int {
+	n := 0
+	for _, p := range s.Params {
+		if !p.Optional {
+			n++
+		}
+	}
+
+	return n
+}
+
+// MaxArgs is the number of arguments which may be passed, or -1 if there is no limit
+func (s Signature) MaxArgs() int {
+	for _, p := range s.Params {
+		if p.Variadic {
+			return -1
+		}
+	}
+
+	return len(s.Params)
+}
+
+// Param returns the parameter that the argument at index i (0 based) is passed to
+func (s Signature) Param(i int) (param Param, ok bool) {
+	if i < len(s.Params) {
+		return s.Params[i], true
+	}
+
+	first := len(s.Params)
+	for first > 0 && s.Params[first-1].Variadic {
+		first--
+	}
+	group := s.Params[first:]
+	if len(group) == 0 {
+		return
+	}
+
+	return group[(i-first)%len(group)], true
+}
+
+// checkArgs checks the number and static types of the args to a call to this function
+func (s Signature) checkArgs(node Node) *SyntaxError {
+	args := node.Args
+	min, max := s.MinArgs(), s.MaxArgs()
+	if len(args) < min || (max >= 0 && len(args) > max) {
+		var expect string
+		switch {
+		case max < 0:
+			expect = fmt.Sprintf("at least %d", min)
+		case min == max:
+			expect = fmt.Sprintf("%d", min)
+		case min+1 == max:
+			expect = fmt.Sprintf("%d or %d", min, max)
+		default:
+			expect = fmt.Sprintf("%d to %d", min, max)
+		}
+		noun := "arguments"
+		if expect == "1" {
+			noun = "argument"
+		}
+		return &SyntaxError{
+			Code:    CodeArgumentCount,
+			Message: fmt.Sprintf("%s expects %s %s, got %d", s.Name, expect, noun, len(args)),
+			Token:   s.Name,
+			Span:    node.Span,
+		}
+	}
+
+	for i, arg := range args {
+		param, _ := s.Param(i)
+		if t, ok := staticType(arg); ok && !param.Kind.Accepts(t) {
+			return &SyntaxError{
+				Code:    CodeArgumentType,
+				Message: fmt.Sprintf("%s expects %s to be %s, got %s", s.Name, param.Name, param.Kind, t),
+				Token:   s.Name,
+				Span:    arg.Span,
+			}
+		}
+	}
+
+	return nil
+}
+
+// staticType returns the type a node will evaluate to, without evaluating it
+// ok is false when the type can't be known until the node is evaluated
+func staticType(node Node) (t Type, ok bool) {
+	switch node.Exp {
+	case "VALUE", "IDENT":
+		return node.Value.typ, node.Value.typ != TypeNull
+	case "<", "<=", ">", ">=", "=", "<>", "!=", "^=", "NOT", "AND", "OR":
+		return TypeInteger, true
+	case "||":
+		return TypeString, true
+	case "/":
+		return TypeDouble, true
+	case "+", "-", "*", "%":
+		if len(node.Args) == 1 {
+			return staticType(node.Args[0])
+		}
+		a, okA := staticType(node.Args[0])
+		b, okB := staticType(node.Args[1])
+		if okA && okB && a.IsNumeric() && b.IsNumeric() {
+			return widestType(a, b), true
+		}
+		return
+	case "SYSDATE", "SESSTARTTIME", "WORKFLOWSTARTTIME":
+		return TypeDate, true
+	}
+
+	if s, found := functions[node.Exp]; found {
+		if s.ReturnsArg > 0 {
+			if len(node.Args) >= s.ReturnsArg {
+				return staticType(node.Args[s.ReturnsArg-1])
+			}
+			return
+		}
+		return s.Returns, s.Returns != TypeNull
+	}
+
+	return
+}
+
+// resultType returns the type a node will evaluate to, or TypeNull if it isn't known until the node is evaluated
+func resultType(node Node) Type {
+	t, _ := staticType(node)
+	return t
+}
+
+// shorthands for declaring parameters
+func required(name string, kind Kind) Param { return Param{Name: name, Kind: kind} }
+func optional(name string, kind Kind) Param { return Param{Name: name, Kind: kind, Optional: true} }
+func variadic(name string, kind Kind) Param { return Param{Name: name, Kind: kind, Variadic: true} }
+
+// all Informatica functions
+// https://docs.informatica.com/data-integration/powercenter/10-4-0/transformation-language-reference/functions.html
+var functions map[string]Signature
+
+func init() {
+	numeric := []Param{required("numeric_value", KindNumeric)}
+	str := []Param{required("string", KindString)}
+	aggregate := []Param{required("value", KindAny), optional("filter_condition", KindNumeric)}
+	numericAggregate := []Param{required("numeric_value", KindNumeric), optional("filter_condition", KindNumeric)}
+	financial := func(names ...string) []Param {
+		params := make([]Param, 0)
+		for _, name := range names {
+			params = append(params, required(name, KindNumeric))
+		}
+		return append(params, optional("future_value", KindNumeric), optional("type", KindNumeric))
+	}
+
+	functions = map[string]Signature{
+		"ABORT": {Params: []Param{required("string", KindString)}, SideEffect: true},
+		"ABS":   {Params: numeric, ReturnsArg: 1},
+		"ADD_TO_DATE": {
+			Params:  []Param{required("date", KindDate), required("format", KindString), required("amount", KindNumeric)},
+			Returns: TypeDate,
+		},
+		"AES_DECRYPT": {
+			Params:  []Param{required("value", KindBinary|KindString), required("key", KindString)},
+			Returns: TypeBinary,
+		},
+		"AES_ENCRYPT": {
+			Params:  []Param{required("value", KindBinary|KindString), required("key", KindString)},
+			Returns: TypeBinary,
+		},
+		"ASCII":          {Params: str, Returns: TypeInteger},
+		"AVG":            {Params: numericAggregate, Returns: TypeDouble, Aggregate: true},
+		"BINARY_COMPARE": {Params: []Param{required("value1", KindBinary), required("value2", KindBinary)}, Returns: TypeInteger},
+		"BINARY_CONCAT":  {Params: []Param{required("value1", KindBinary), required("value2", KindBinary)}, Returns: TypeBinary},
+		"BINARY_LENGTH":  {Params: []Param{required("value", KindBinary)}, Returns: TypeInteger},
+		"BINARY_SECTION": {
+			Params: []Param{
+				required("value", KindBinary), required("start", KindNumeric), optional("length", KindNumeric),
+			},
+			Returns: TypeBinary,
+		},
+		"CEIL":    {Params: numeric, ReturnsArg: 1},
+		"CHOOSE":  {Params: []Param{required("index", KindNumeric), variadic("string", KindAny)}, ReturnsArg: 2},
+		"CHR":     {Params: numeric, Returns: TypeString},
+		"CHRCODE": {Params: str, Returns: TypeInteger},
+		"COMPRESS": {
+			Params:  []Param{required("value", KindString|KindBinary)},
+			Returns: TypeBinary,
+		},
+		"CONCAT": {
+			Params:  []Param{required("first_string", KindString), required("second_string", KindString)},
+			Returns: TypeString,
+		},
+		"CONVERT_BASE": {
+			Params: []Param{
+				required("value", KindString), required("source_base", KindNumeric), required("dest_base", KindNumeric),
+			},
+			Returns: TypeString,
+		},
+		"COS":   {Params: numeric, Returns: TypeDouble},
+		"COSH":  {Params: numeric, Returns: TypeDouble},
+		"COUNT": {Params: aggregate, Returns: TypeInteger, Aggregate: true},
+		"CRC32": {Params: []Param{required("value", KindString|KindBinary)}, Returns: TypeBigint},
+		"CUME":  {Params: numericAggregate, Returns: TypeDouble, Window: true},
+		"DATE_COMPARE": {
+			Params:  []Param{required("date1", KindDate), required("date2", KindDate)},
+			Returns: TypeInteger,
+		},
+		"DATE_DIFF": {
+			Params:  []Param{required("date1", KindDate), required("date2", KindDate), required("format", KindString)},
+			Returns: TypeDouble,
+		},
+		"DEC_BASE64": {Params: []Param{required("value", KindString)}, Returns: TypeBinary},
+		"DEC_HEX":    {Params: []Param{required("value", KindString)}, Returns: TypeString},
+		"DECODE": {
+			Params: []Param{
+				required("value", KindAny), variadic("search", KindAny), variadic("result", KindAny),
+			},
+			ReturnsArg: 3,
+		},
+		"DECOMPRESS": {
+			Params:  []Param{required("value", KindBinary), optional("precision", KindNumeric)},
+			Returns: TypeBinary,
+		},
+		"EBCDIC_ISO88591": {Params: []Param{required("value", KindString|KindBinary)}, Returns: TypeString},
+		"ENC_BASE64":      {Params: []Param{required("value", KindString|KindBinary)}, Returns: TypeString},
+		"ENC_HEX":         {Params: []Param{required("value", KindString|KindBinary)}, Returns: TypeString},
+		"ERROR":           {Params: []Param{required("string", KindString)}, SideEffect: true},
+		"EXP":             {Params: []Param{required("exponent", KindNumeric)}, Returns: TypeDouble},
+		"FIRST":           {Params: aggregate, ReturnsArg: 1, Aggregate: true},
+		"FLOOR":           {Params: numeric, ReturnsArg: 1},
+		"FV": {
+			Params: []Param{
+				required("rate", KindNumeric), required("terms", KindNumeric), required("payment", KindNumeric),
+				optional("present_value", KindNumeric), optional("type", KindNumeric),
+			},
+			Returns: TypeDouble,
+		},
+		"GET_DATE_PART": {
+			Params:  []Param{required("date", KindDate), required("format", KindString)},
+			Returns: TypeInteger,
+		},
+		"GREATEST": {Params: []Param{required("value1", KindAny), variadic("value", KindAny)}, ReturnsArg: 1},
+		"IIF": {
+			Params: []Param{
+				required("condition", KindNumeric), required("value1", KindAny), optional("value2", KindAny),
+			},
+			ReturnsArg: 2,
+		},
+		"IN": {
+			Params:  []Param{required("valueToSearch", KindAny), variadic("value", KindAny)},
+			Returns: TypeInteger,
+		},
+		"INDEXOF": {
+			Params:  []Param{required("valueToSearch", KindString), variadic("string", KindString|KindNumeric)},
+			Returns: TypeInteger,
+		},
+		"INITCAP": {Params: str, Returns: TypeString},
+		"INSTR": {
+			Params: []Param{
+				required("string", KindString), required("search_value", KindString), optional("start", KindNumeric),
+				optional("occurrence", KindNumeric), optional("comparison_type", KindNumeric),
+			},
+			Returns: TypeInteger,
+		},
+		"ISNULL":    {Params: []Param{required("value", KindAny)}, Returns: TypeInteger},
+		"IS_DATE":   {Params: []Param{required("value", KindString), optional("format", KindString)}, Returns: TypeInteger},
+		"IS_NUMBER": {Params: []Param{required("value", KindString)}, Returns: TypeInteger},
+		"IS_SPACES": {Params: []Param{required("value", KindString)}, Returns: TypeInteger},
+		"LAG": {
+			Params:     []Param{required("value", KindAny), optional("offset", KindNumeric), optional("default", KindAny)},
+			ReturnsArg: 1,
+			Window:     true,
+		},
+		"LAST":     {Params: aggregate, ReturnsArg: 1, Aggregate: true},
+		"LAST_DAY": {Params: []Param{required("date", KindDate)}, Returns: TypeDate},
+		"LEAD": {
+			Params:     []Param{required("value", KindAny), optional("offset", KindNumeric), optional("default", KindAny)},
+			ReturnsArg: 1,
+			Window:     true,
+		},
+		"LEAST":  {Params: []Param{required("value1", KindAny), variadic("value", KindAny)}, ReturnsArg: 1},
+		"LENGTH": {Params: str, Returns: TypeInteger},
+		"LN":     {Params: numeric, Returns: TypeDouble},
+		"LOG": {
+			Params:  []Param{required("base", KindNumeric), required("exponent", KindNumeric)},
+			Returns: TypeDouble,
+		},
+		"LOOKUP": {
+			Params: []Param{
+				required("result", KindAny), variadic("search", KindAny), variadic("value", KindAny),
+			},
+			ReturnsArg: 1,
+		},
+		"LOWER": {Params: str, Returns: TypeString},
+		"LPAD": {
+			Params: []Param{
+				required("first_string", KindString), required("length", KindNumeric),
+				optional("second_string", KindString),
+			},
+			Returns: TypeString,
+		},
+		"LTRIM": {Params: []Param{required("string", KindString), optional("trim_set", KindString)}, Returns: TypeString},
+		"MAKE_DATE_TIME": {
+			Params: []Param{
+				required("year", KindNumeric), required("month", KindNumeric), required("day", KindNumeric),
+				optional("hour", KindNumeric), optional("minute", KindNumeric), optional("second", KindNumeric),
+				optional("nanosecond", KindNumeric),
+			},
+			Returns: TypeDate,
+		},
+		"MAX":    {Params: aggregate, ReturnsArg: 1, Aggregate: true},
+		"MD5":    {Params: []Param{required("value", KindString|KindBinary)}, Returns: TypeString},
+		"MEDIAN": {Params: numericAggregate, Returns: TypeDouble, Aggregate: true},
+		"METAPHONE": {
+			Params:  []Param{required("string", KindString), optional("length", KindNumeric)},
+			Returns: TypeString,
+		},
+		"MIN": {Params: aggregate, ReturnsArg: 1, Aggregate: true},
+		"MOD": {
+			Params:     []Param{required("numeric_value", KindNumeric), required("divisor", KindNumeric)},
+			ReturnsArg: 1,
+		},
+		"MOVINGAVG": {
+			Params: []Param{
+				required("numeric_value", KindNumeric), required("rowset", KindNumeric),
+				optional("filter_condition", KindNumeric),
+			},
+			Returns: TypeDouble,
+			Window:  true,
+		},
+		"MOVINGSUM": {
+			Params: []Param{
+				required("numeric_value", KindNumeric), required("rowset", KindNumeric),
+				optional("filter_condition", KindNumeric),
+			},
+			Returns: TypeDouble,
+			Window:  true,
+		},
+		"NPER": {Params: financial("rate", "present_value", "payment"), Returns: TypeDouble},
+		"PERCENTILE": {
+			Params: []Param{
+				required("numeric_value", KindNumeric), required("percentile", KindNumeric),
+				optional("filter_condition", KindNumeric),
+			},
+			Returns:   TypeDouble,
+			Aggregate: true,
+		},
+		"PMT": {Params: financial("rate", "terms", "present_value"), Returns: TypeDouble},
+		"POWER": {
+			Params:  []Param{required("base", KindNumeric), required("exponent", KindNumeric)},
+			Returns: TypeDouble,
+		},
+		"PV":   {Params: financial("rate", "terms", "payment"), Returns: TypeDouble},
+		"RAND": {Params: []Param{optional("seed", KindNumeric)}, Returns: TypeDouble},
+		"RATE": {Params: financial("terms", "payment", "present_value"), Returns: TypeDouble},
+		"REG_EXTRACT": {
+			Params: []Param{
+				required("subject", KindString), required("pattern", KindString),
+				optional("subPatternNum", KindNumeric), optional("match_from_start", KindNumeric),
+			},
+			Returns: TypeString,
+		},
+		"REG_MATCH": {
+			Params:  []Param{required("subject", KindString), required("pattern", KindString)},
+			Returns: TypeInteger,
+		},
+		"REG_REPLACE": {
+			Params: []Param{
+				required("subject", KindString), required("pattern", KindString), required("replace", KindString),
+				optional("numReplacements", KindNumeric),
+			},
+			Returns: TypeString,
+		},
+		"REPLACECHR": {
+			Params: []Param{
+				required("CaseFlag", KindNumeric), required("InputString", KindString),
+				required("OldCharSet", KindString), required("NewChar", KindString),
+			},
+			Returns: TypeString,
+		},
+		"REPLACESTR": {
+			Params: []Param{
+				required("CaseFlag", KindNumeric), required("InputString", KindString),
+				required("OldString", KindString), variadic("NewString", KindString),
+			},
+			Returns: TypeString,
+		},
+		"REVERSE": {Params: str, Returns: TypeString},
+		"ROUND": {
+			Params:     []Param{required("value", KindDate|KindNumeric), optional("precision", KindString|KindNumeric)},
+			ReturnsArg: 1,
+		},
+		"RPAD": {
+			Params: []Param{
+				required("first_string", KindString), required("length", KindNumeric),
+				optional("second_string", KindString),
+			},
+			Returns: TypeString,
+		},
+		"RTRIM": {Params: []Param{required("string", KindString), optional("trim_set", KindString)}, Returns: TypeString},
+		"SETCOUNTVARIABLE": {
+			Params:     []Param{required("$$Variable", KindAny)},
+			Returns:    TypeInteger,
+			SideEffect: true,
+		},
+		"SET_DATE_PART": {
+			Params: []Param{
+				required("date", KindDate), required("format", KindString), required("value", KindNumeric),
+			},
+			Returns: TypeDate,
+		},
+		"SETMAXVARIABLE": {
+			Params:     []Param{required("$$Variable", KindAny), required("value", KindAny)},
+			ReturnsArg: 2,
+			SideEffect: true,
+		},
+		"SETMINVARIABLE": {
+			Params:     []Param{required("$$Variable", KindAny), required("value", KindAny)},
+			ReturnsArg: 2,
+			SideEffect: true,
+		},
+		"SETVARIABLE": {
+			Params:     []Param{required("$$Variable", KindAny), required("value", KindAny)},
+			ReturnsArg: 2,
+			SideEffect: true,
+		},
+		"SHA256":  {Params: []Param{required("value", KindString|KindBinary)}, Returns: TypeString},
+		"SIGN":    {Params: numeric, Returns: TypeInteger},
+		"SIN":     {Params: numeric, Returns: TypeDouble},
+		"SINH":    {Params: numeric, Returns: TypeDouble},
+		"SOUNDEX": {Params: str, Returns: TypeString},
+		"SQRT":    {Params: numeric, Returns: TypeDouble},
+		"STDDEV":  {Params: numericAggregate, Returns: TypeDouble, Aggregate: true},
+		"SUBSTR": {
+			Params: []Param{
+				required("string", KindString), required("start", KindNumeric), optional("length", KindNumeric),
+			},
+			Returns: TypeString,
+		},
+		"SUM":          {Params: numericAggregate, ReturnsArg: 1, Aggregate: true},
+		"SYSTIMESTAMP": {Params: []Param{optional("precision", KindString)}, Returns: TypeDate},
+		"TAN":          {Params: numeric, Returns: TypeDouble},
+		"TANH":         {Params: numeric, Returns: TypeDouble},
+		"TIME_RANGE": {
+			Params:  []Param{required("date", KindDate), required("start", KindDate), required("end", KindDate)},
+			Returns: TypeInteger,
+		},
+		"TO_BIGINT": {Params: []Param{required("value", KindString), optional("flag", KindNumeric)}, Returns: TypeBigint},
+		"TO_CHAR": {
+			Params:  []Param{required("value", KindDate|KindNumeric|KindString), optional("format", KindString)},
+			Returns: TypeString,
+		},
+		"TO_DATE":    {Params: []Param{required("string", KindString), optional("format", KindString)}, Returns: TypeDate},
+		"TO_DECIMAL": {Params: []Param{required("value", KindString), optional("scale", KindNumeric)}, Returns: TypeDecimal},
+		"TO_FLOAT":   {Params: []Param{required("value", KindString)}, Returns: TypeDouble},
+		"TO_INTEGER": {Params: []Param{required("value", KindString), optional("flag", KindNumeric)}, Returns: TypeInteger},
+		"TRUNC": {
+			Params:     []Param{required("value", KindDate|KindNumeric), optional("precision", KindString|KindNumeric)},
+			ReturnsArg: 1,
+		},
+		"UPPER":    {Params: str, Returns: TypeString},
+		"VARIANCE": {Params: numericAggregate, Returns: TypeDouble, Aggregate: true},
+	}
+
+	for name, s := range functions {
+		s.Name = name
+		functions[name] = s
+	}
+}
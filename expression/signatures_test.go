@@ -0,0 +1,165 @@
+package expression
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSignaturesWellFormed(t *testing.T) {
+	for name, s := range functions {
+		if s.Name != name {
+			t.Errorf("%s has the name %s", name, s.Name)
+		}
+
+		optional, variadic := false, false
+		for _, p := range s.Params {
+			if p.Optional {
+				optional = true
+			} else if optional && !p.Variadic {
+				t.Errorf("%s has the required param %s after an optional param", name, p.Name)
+			}
+			if p.Variadic {
+				variadic = true
+			} else if variadic {
+				t.Errorf("%s has the param %s after a variadic param", name, p.Name)
+			}
+		}
+
+		if s.ReturnsArg > len(s.Params) {
+			t.Errorf("%s returns the type of arg %d but only has %d params", name, s.ReturnsArg, len(s.Params))
+		}
+	}
+
+	// every implemented function must be declared
+	for name := range fns {
+		if _, ok := functions[name]; !ok && !isLiteral(name) {
+			t.Errorf("%s is implemented but has no signature", name)
+		}
+	}
+	for name := range lazyFns {
+		if _, ok := functions[name]; !ok {
+			t.Errorf("%s is implemented but has no signature", name)
+		}
+	}
+}
+
+func TestSignatureParam(t *testing.T) {
+	testCases := []struct {
+		function string
+		index    int
+		expect   string
+	}{
+		{"SUBSTR", 0, "string"},
+		{"SUBSTR", 2, "length"},
+		{"DECODE", 0, "value"},
+		{"DECODE", 1, "search"},
+		{"DECODE", 2, "result"},
+		{"DECODE", 3, "search"},
+		{"DECODE", 6, "result"},
+		{"CHOOSE", 5, "string"},
+	}
+
+	for _, tc := range testCases {
+		p, ok := functions[tc.function].Param(tc.index)
+		if !ok || p.Name != tc.expect {
+			t.Errorf("For %s arg %d, expected %s but got %s", tc.function, tc.index, tc.expect, p.Name)
+		}
+	}
+
+	if _, ok := functions["SUBSTR"].Param(3); ok {
+		t.Error("SUBSTR should not have a 4th param")
+	}
+}
+
+func TestArgumentErrors(t *testing.T) {
+	testCases := []struct {
+		input  string
+		expect string
+	}{
+		{`SUBSTR('abc', 1, 2, 3)`, `SUBSTR expects 2 or 3 arguments, got 4`},
+		{`SUBSTR('abc')`, `SUBSTR expects 2 or 3 arguments, got 1`},
+		{`ABS(1, 2)`, `ABS expects 1 argument, got 2`},
+		{`ABS()`, `ABS expects 1 argument, got 0`},
+		{`CONCAT('a')`, `CONCAT expects 2 arguments, got 1`},
+		{`RTRIM('a', 'b', 'c')`, `RTRIM expects 1 or 2 arguments, got 3`},
+		{`DECODE(1, 2)`, `DECODE expects at least 3 arguments, got 2`},
+		{`INSTR('a')`, `INSTR expects 2 to 5 arguments, got 1`},
+		{`ADD_TO_DATE('01/01/2020', 'DD', 1)`, `ADD_TO_DATE expects date to be a date, got STRING`},
+		{`ABS(d)`, `ABS expects numeric_value to be a number, got DATE/TIME`},
+		{`BINARY_LENGTH('abc')`, `BINARY_LENGTH expects value to be a binary, got STRING`},
+		{`IIF(d, 1, 2)`, `IIF expects condition to be a number, got DATE/TIME`},
+		{`SUBSTR('abc', d)`, `SUBSTR expects start to be a number, got DATE/TIME`},
+	}
+
+	vars := []Variable{
+		{"d", "DATE", "01/31/2020"},
+	}
+
+	for _, tc := range testCases {
+		_, err := parse([]byte(tc.input), vars)
+		if err == nil {
+			t.Errorf("Input: %s\nExpected an error", tc.input)
+			continue
+		}
+
+		if !strings.HasSuffix(err.Error(), tc.expect) {
+			t.Errorf("Input: %s\nExpected: `%s`, got `%s`", tc.input, tc.expect, err)
+		}
+	}
+}
+
+func TestArgumentTypes(t *testing.T) {
+	// strings and numbers are converted implicitly, and NULL can be passed to anything
+	testCases := []string{
+		`LTRIM(1)`,
+		`ABS('1')`,
+		`ABS(NULL)`,
+		`ADD_TO_DATE(NULL, 'DD', 1)`,
+		`IIF(1 < 2, d, NULL)`,
+		`ABS(IIF(TRUE, 1, 2))`,
+	}
+
+	vars := []Variable{
+		{"d", "DATE", "01/31/2020"},
+	}
+
+	for _, tc := range testCases {
+		if _, err := parse([]byte(tc), vars); err != nil {
+			t.Errorf("Input: %s\nUnexpected error: %v", tc, err)
+		}
+	}
+}
+
+func TestStaticType(t *testing.T) {
+	testCases := []struct {
+		input  string
+		expect Type
+		ok     bool
+	}{
+		{`1`, TypeInteger, true},
+		{`1 + 2.5`, TypeDecimal, true},
+		{`'a' || 'b'`, TypeString, true},
+		{`ABS(1.5)`, TypeDecimal, true},
+		{`IIF(TRUE, 'a', 'b')`, TypeString, true},
+		{`DECODE(1, 1, d)`, TypeDate, true},
+		{`LENGTH('abc')`, TypeInteger, true},
+		{`NULL`, TypeNull, false},
+		{`ERROR('x')`, TypeNull, false},
+	}
+
+	vars := []Variable{
+		{"d", "DATE", "01/31/2020"},
+	}
+
+	for _, tc := range testCases {
+		node, err := parse([]byte(tc.input), vars)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+
+		if typ, ok := staticType(node); typ != tc.expect || ok != tc.ok {
+			t.Errorf("Input: %s\nExpected: %s %v, got %s %v", tc.input, tc.expect, tc.ok, typ, ok)
+		}
+	}
+}
@@ -0,0 +1,452 @@
+// string functions
+// https://docs.informatica.com/data-integration/powercenter/10-4-0/transformation-language-reference/functions.html
+
+package expression
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// toPosition converts a numeric argument used as a position or length to an int, rounding decimals to the nearest
+// integer as PowerCenter does
+func toPosition(fn string, v Value) (i int, err error) {
+	n, err := toNumeric(fn, v)
+	if err != nil {
+		return
+	}
+
+	f := math.Round(n.Float())
+	if f > math.MaxInt32 || f < math.MinInt32 {
+		err = &RowError{Function: fn, Message: fmt.Sprintf("%s is out of range", v)}
+		return
+	}
+	i = int(f)
+
+	return
+}
+
+// anyNull checks if any of the values are NULL; most functions return NULL when one of their arguments is
+func anyNull(args []Value) bool {
+	for _, arg := range args {
+		if arg.IsNull() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ascii returns the numeric value of the first character of the string, or NULL for an empty string
+func ascii(args ...Value) (result Value, err error) {
+	return firstCharCode(args[0])
+}
+
+// chrCode returns the Unicode value of the first character of the string
+func chrCode(args ...Value) (result Value, err error) {
+	return firstCharCode(args[0])
+}
+
+func firstCharCode(v Value) (result Value, err error) {
+	if v.IsNull() || toText(v) == "" {
+		result = NewNull(TypeInteger)
+		return
+	}
+
+	r, _ := utf8.DecodeRuneInString(toText(v))
+	result = NewInteger(int32(r))
+
+	return
+}
+
+// initCap capitalizes the first letter of each word and lowercases the rest
+// Words are delimited by white space and by any character which isn't a letter or digit, so o'malley becomes O'Malley
+func initCap(args ...Value) (result Value, err error) {
+	if args[0].IsNull() {
+		result = NewNull(TypeString)
+		return
+	}
+
+	var b strings.Builder
+	startOfWord := true
+	for _, r := range toText(args[0]) {
+		if startOfWord {
+			b.WriteRune(unicode.ToUpper(r))
+		} else {
+			b.WriteRune(unicode.ToLower(r))
+		}
+		startOfWord = !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}
+	result = NewString(b.String())
+
+	return
+}
+
+// indexOf returns the position of the first string in the list that matches valueToSearch, or 0 if none match
+// A trailing number is the CaseFlag: 0 or NULL is case insensitive, anything else is case sensitive
+func indexOf(env *environment, args ...Node) (result Value, err error) {
+	value, err := evaluateNode(args[0], env)
+	if err != nil {
+		return
+	}
+
+	list, caseSensitive, err := caseFlag("INDEXOF", env, args[1:])
+	if err != nil {
+		return
+	}
+
+	if value.IsNull() {
+		result = NewNull(TypeInteger)
+		return
+	}
+
+	for i, arg := range list {
+		item, iErr := evaluateNode(arg, env)
+		if iErr != nil {
+			err = iErr
+			return
+		}
+
+		if item.IsNull() {
+			continue
+		}
+
+		if caseSensitive && toText(item) == toText(value) ||
+			!caseSensitive && strings.EqualFold(toText(item), toText(value)) {
+			result = NewInteger(int32(i + 1))
+			return
+		}
+	}
+	result = NewInteger(0)
+
+	return
+}
+
+// inStr returns the position of an occurrence of search_value in the string, or 0 if it isn't found
+// A negative start counts back from the end of the string and searches towards the beginning
+func inStr(args ...Value) (result Value, err error) {
+	if anyNull(args) {
+		result = NewNull(TypeInteger)
+		return
+	}
+
+	s, search := []rune(toText(args[0])), []rune(toText(args[1]))
+	start, occurrence := 1, 1
+	if len(args) > 2 {
+		if start, err = toPosition("INSTR", args[2]); err != nil {
+			return
+		}
+	}
+	if len(args) > 3 {
+		if occurrence, err = toPosition("INSTR", args[3]); err != nil {
+			return
+		}
+		if occurrence <= 0 {
+			err = &RowError{
+				Function: "INSTR",
+				Message:  fmt.Sprintf("occurrence must be greater than 0, got %d", occurrence),
+			}
+			return
+		}
+	}
+	// comparison_type chooses between linguistic and binary comparisons; both compare characters exactly here
+
+	matchAt := func(i int) bool {
+		if i+len(search) > len(s) {
+			return false
+		}
+		for j, r := range search {
+			if s[i+j] != r {
+				return false
+			}
+		}
+		return true
+	}
+
+	found := 0
+	if start >= 0 {
+		if start == 0 {
+			start = 1
+		}
+		for i := start - 1; i < len(s); i++ {
+			if matchAt(i) {
+				if found++; found == occurrence {
+					result = NewInteger(int32(i + 1))
+					return
+				}
+			}
+		}
+	} else {
+		for i := len(s) + start; i >= 0; i-- {
+			if matchAt(i) {
+				if found++; found == occurrence {
+					result = NewInteger(int32(i + 1))
+					return
+				}
+			}
+		}
+	}
+	result = NewInteger(0)
+
+	return
+}
+
+// isSpaces returns TRUE (1) if the string consists entirely of spaces (blank, tab, newline, carriage return, formfeed
+// or vertical tab) and FALSE (0) otherwise, including for an empty string
+func isSpaces(args ...Value) (result Value, err error) {
+	if args[0].IsNull() {
+		result = NewNull(TypeInteger)
+		return
+	}
+
+	s := toText(args[0])
+	result = boolValue(s != "" && strings.Trim(s, " \t\n\r\f\v") == "")
+
+	return
+}
+
+// length returns the number of characters in the string
+func length(args ...Value) (result Value, err error) {
+	if args[0].IsNull() {
+		result = NewNull(TypeInteger)
+		return
+	}
+
+	result = NewInteger(int32(utf8.RuneCountInString(toText(args[0]))))
+
+	return
+}
+
+func lower(args ...Value) (result Value, err error) {
+	return mapString(args[0], strings.ToLower)
+}
+
+func upper(args ...Value) (result Value, err error) {
+	return mapString(args[0], strings.ToUpper)
+}
+
+// reverse the characters of the string
+func reverse(args ...Value) (result Value, err error) {
+	return mapString(args[0], func(s string) string {
+		r := []rune(s)
+		for i, j := 0, len(r)-1; i < j; i, j = i+1, j-1 {
+			r[i], r[j] = r[j], r[i]
+		}
+		return string(r)
+	})
+}
+
+// mapString applies fn to a string argument, returning NULL for NULL
+func mapString(v Value, fn func(s string) string) (result Value, err error) {
+	if v.IsNull() {
+		result = NewNull(TypeString)
+		return
+	}
+
+	result = NewString(fn(toText(v)))
+
+	return
+}
+
+func lpad(args ...Value) (result Value, err error) {
+	return pad("LPAD", args, true)
+}
+
+func rpad(args ...Value) (result Value, err error) {
+	return pad("RPAD", args, false)
+}
+
+// pad the string to length characters by repeating second_string (a blank by default) on the left or right
+// A string longer than length is truncated to its first length characters, e.g. LPAD('alphabetical', 5, 'x') is alpha
+func pad(fn string, args []Value, left bool) (result Value, err error) {
+	if anyNull(args) {
+		result = NewNull(TypeString)
+		return
+	}
+
+	s := []rune(toText(args[0]))
+	n, err := toPosition(fn, args[1])
+	if err != nil {
+		return
+	}
+	if n < 0 {
+		result = NewNull(TypeString)
+		return
+	}
+	padding := []rune(" ")
+	if len(args) > 2 {
+		padding = []rune(toText(args[2]))
+	}
+
+	if n <= len(s) || len(padding) == 0 {
+		if n < len(s) {
+			s = s[:n]
+		}
+		result = NewString(string(s))
+		return
+	}
+
+	fill := make([]rune, n-len(s))
+	for i := range fill {
+		fill[i] = padding[i%len(padding)]
+	}
+	if left {
+		result = NewString(string(fill) + string(s))
+	} else {
+		result = NewString(string(s) + string(fill))
+	}
+
+	return
+}
+
+// replaceChr replaces each character of InputString that is in OldCharSet with NewChar, or removes it when NewChar is
+// NULL or empty; only the first character of NewChar is used
+func replaceChr(args ...Value) (result Value, err error) {
+	if args[1].IsNull() {
+		result = NewNull(TypeString)
+		return
+	}
+
+	caseSensitive, err := caseFlagValue("REPLACECHR", args[0])
+	if err != nil {
+		return
+	}
+	input := toText(args[1])
+	if args[2].IsNull() || toText(args[2]) == "" {
+		result = NewString(input)
+		return
+	}
+	oldChars := toText(args[2])
+	if !caseSensitive {
+		oldChars = strings.ToLower(oldChars) + strings.ToUpper(oldChars)
+	}
+	var newChar []rune
+	if !args[3].IsNull() {
+		if r := []rune(toText(args[3])); len(r) > 0 {
+			newChar = r[:1]
+		}
+	}
+
+	var b strings.Builder
+	for _, r := range input {
+		if strings.ContainsRune(oldChars, r) {
+			b.WriteString(string(newChar))
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	result = NewString(b.String())
+
+	return
+}
+
+// replaceStr replaces each OldString in InputString with NewString, the last argument
+// The input is scanned once from left to right; at each position the OldStrings are tried in the order they were
+// given, and after a replacement the search continues after the replaced characters
+// NULL or empty OldStrings are ignored and a NULL or empty NewString removes the OldStrings
+func replaceStr(args ...Value) (result Value, err error) {
+	if args[1].IsNull() {
+		result = NewNull(TypeString)
+		return
+	}
+
+	caseSensitive, err := caseFlagValue("REPLACESTR", args[0])
+	if err != nil {
+		return
+	}
+	input := toText(args[1])
+	newString := ""
+	if last := args[len(args)-1]; !last.IsNull() {
+		newString = toText(last)
+	}
+	var olds []string
+	for _, arg := range args[2 : len(args)-1] {
+		if !arg.IsNull() && toText(arg) != "" {
+			olds = append(olds, toText(arg))
+		}
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(input); {
+		replaced := false
+		for _, old := range olds {
+			if i+len(old) > len(input) {
+				continue
+			}
+			candidate := input[i : i+len(old)]
+			if candidate == old || !caseSensitive && strings.EqualFold(candidate, old) {
+				b.WriteString(newString)
+				i += len(old)
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			_, size := utf8.DecodeRuneInString(input[i:])
+			b.WriteString(input[i : i+size])
+			i += size
+		}
+	}
+	result = NewString(b.String())
+
+	return
+}
+
+// substr returns length characters of the string starting at start
+// A start of 0 is the same as 1 and a negative start counts back from the end of the string, e.g. -1 is the last
+// character; an empty string is returned when start is past either end or length is less than 1
+func substr(args ...Value) (result Value, err error) {
+	if anyNull(args) {
+		result = NewNull(TypeString)
+		return
+	}
+
+	s := []rune(toText(args[0]))
+	from, to, err := section("SUBSTR", len(s), args[1:])
+	if err != nil {
+		return
+	}
+	result = NewString(string(s[from:to]))
+
+	return
+}
+
+// section returns the part of a string or binary of the given length that SUBSTR and BINARY_SECTION return, from a
+// start (1 based, 0 meaning 1 and negative counting back from the end) and an optional length
+// A start outside of the value or a length below 1 gives an empty section
+func section(fn string, length int, args []Value) (from int, to int, err error) {
+	start, err := toPosition(fn, args[0])
+	if err != nil {
+		return
+	}
+	switch {
+	case start == 0:
+		start = 1
+	case start < 0:
+		start = length + start + 1
+	}
+	if start < 1 || start > length {
+		return
+	}
+
+	from, to = start-1, length
+	if len(args) > 1 {
+		n, lErr := toPosition(fn, args[1])
+		if lErr != nil {
+			err = lErr
+			return
+		}
+		if n < 1 {
+			to = from
+			return
+		}
+		if from+n < to {
+			to = from + n
+		}
+	}
+
+	return
+}
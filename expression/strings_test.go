@@ -0,0 +1,187 @@
+package expression
+
+import (
+	"testing"
+)
+
+func TestASCII(t *testing.T) {
+	runStringTests(t, []stringTestCase{
+		{`ASCII('Flashlight')`, `70`},
+		{`ASCII('a')`, `97`},
+		{`ASCII(5)`, `53`},
+		{`ASCII('')`, `NULL`},
+		{`ASCII(NULL)`, `NULL`},
+		{`CHRCODE('€uro')`, `8364`},
+	})
+}
+
+func TestINITCAP(t *testing.T) {
+	testCases := []stringTestCase{
+		{`INITCAP('ROBERT james')`, `Robert James`},
+		{`INITCAP(in_NAME)`, `O'Malley`},
+		{`INITCAP('hello-world.foo_bar')`, `Hello-World.Foo_Bar`},
+		{`INITCAP('1st place  2ND')`, `1st Place  2nd`},
+		{`INITCAP('tab	separated')`, `Tab	Separated`},
+		{`INITCAP(NULL)`, `NULL`},
+	}
+
+	vars := []Variable{{N: "in_NAME", T: "STRING", V: "o'malley"}}
+
+	for _, tc := range testCases {
+		result, err := Evaluate(tc.input, vars)
+		if err != nil {
+			t.Errorf("Input: %s\nUnexpected error: %v", tc.input, err)
+			continue
+		}
+
+		if result.String() != tc.expect {
+			t.Errorf("Input: %s\nExpected: `%s`, got `%s`", tc.input, tc.expect, result.String())
+		}
+	}
+}
+
+func TestINDEXOF(t *testing.T) {
+	runStringTests(t, []stringTestCase{
+		{`INDEXOF('flashlight', 'diving hood', 'flashlight', 'safety knife')`, `2`},
+		{`INDEXOF('Safety Knife', 'diving hood', 'flashlight', 'safety knife')`, `0`},
+		{`INDEXOF('Safety Knife', 'diving hood', 'flashlight', 'safety knife', 0)`, `3`},
+		{`INDEXOF('Safety Knife', 'diving hood', 'flashlight', 'safety knife', NULL)`, `0`},
+		{`INDEXOF('Safety Knife', 'diving hood', 'flashlight', 'safety knife', 1)`, `0`},
+		{`INDEXOF('a', NULL, 'a')`, `2`},
+		{`INDEXOF(NULL, 'a', 'b')`, `NULL`},
+	})
+}
+
+func TestINSTR(t *testing.T) {
+	runStringTests(t, []stringTestCase{
+		{`INSTR('Blue Fin Aqua Center', 'a')`, `13`},
+		{`INSTR('Blue Fin Aqua Center', 'a', 1, 2)`, `0`},
+		{`INSTR('Maco Shark', 'a', 1, 2)`, `8`},
+		{`INSTR('Maco Shark', 'a', 0)`, `2`},
+		{`INSTR('Maco Shark', 'a', 3)`, `8`},
+		{`INSTR('Maco Shark', 'a', -1)`, `8`},
+		{`INSTR('Maco Shark', 'a', -1, 2)`, `2`},
+		{`INSTR('Maco Shark', 'a', -4)`, `2`},
+		{`INSTR('Maco Shark', 'a', 2.6)`, `8`},
+		{`INSTR('Maco Shark', 'Shark', -1)`, `6`},
+		{`INSTR('aaa', 'aa', 1, 2)`, `2`},
+		{`INSTR('abc', 'z')`, `0`},
+		{`INSTR('abc', 'c', 10)`, `0`},
+		{`INSTR('abc', 'a', 1, 1, 1)`, `1`},
+		{`INSTR(NULL, 'a')`, `NULL`},
+		{`INSTR('abc', NULL)`, `NULL`},
+		{`INSTR('abc', 'a', 1, 0)`, `<<Expression Error>> [INSTR]: occurrence must be greater than 0, got 0`},
+	})
+}
+
+func TestISSPACES(t *testing.T) {
+	runStringTests(t, []stringTestCase{
+		{`IS_SPACES('   ')`, `1`},
+		{`IS_SPACES(' 	')`, `1`},
+		{`IS_SPACES(' a ')`, `0`},
+		{`IS_SPACES('')`, `0`},
+		{`IS_SPACES(NULL)`, `NULL`},
+	})
+}
+
+func TestLENGTH(t *testing.T) {
+	runStringTests(t, []stringTestCase{
+		{`LENGTH('abc')`, `3`},
+		{`LENGTH('')`, `0`},
+		{`LENGTH('naïve')`, `5`},
+		{`LENGTH(12.5)`, `4`},
+		{`LENGTH(NULL)`, `NULL`},
+	})
+}
+
+func TestLOWERUPPER(t *testing.T) {
+	runStringTests(t, []stringTestCase{
+		{`LOWER('Hello World')`, `hello world`},
+		{`UPPER('Hello World')`, `HELLO WORLD`},
+		{`LOWER('ÉCOLE')`, `école`},
+		{`LOWER(NULL)`, `NULL`},
+		{`UPPER(NULL)`, `NULL`},
+	})
+}
+
+func TestPAD(t *testing.T) {
+	runStringTests(t, []stringTestCase{
+		{`LPAD('abc', 6)`, `   abc`},
+		{`LPAD('Flashlight', 16, '*..*')`, `*..**.Flashlight`},
+		{`LPAD('alphabetical', 5, 'x')`, `alpha`},
+		{`LPAD('abc', 3, 'x')`, `abc`},
+		{`LPAD('abc', 0, 'x')`, ``},
+		{`LPAD('abc', -1, 'x')`, `NULL`},
+		{`LPAD('abc', 5, '')`, `abc`},
+		{`LPAD(NULL, 5)`, `NULL`},
+		{`LPAD('abc', 5, NULL)`, `NULL`},
+		{`RPAD('abc', 6)`, `abc   `},
+		{`RPAD('Flashlight', 16, '*..*')`, `Flashlight*..**.`},
+		{`RPAD('alphabetical', 5, 'x')`, `alpha`},
+		{`RPAD('abc', 4.5, 'x')`, `abcxx`},
+		{`RPAD('abc', NULL)`, `NULL`},
+	})
+}
+
+func TestREPLACECHR(t *testing.T) {
+	runStringTests(t, []stringTestCase{
+		{`REPLACECHR(0, 'GET /news/index.html HTTP/1.1', '/', '*')`, `GET *news*index.html HTTP*1.1`},
+		{`REPLACECHR(1, 'abcABC', 'a', 'x')`, `xbcABC`},
+		{`REPLACECHR(0, 'abcABC', 'a', 'x')`, `xbcxBC`},
+		{`REPLACECHR(NULL, 'abcABC', 'a', 'x')`, `xbcxBC`},
+		{`REPLACECHR(1, 'abcABC', 'ab', 'xyz')`, `xxcABC`},
+		{`REPLACECHR(1, 'abcABC', 'ab', NULL)`, `cABC`},
+		{`REPLACECHR(1, 'abcABC', 'ab', '')`, `cABC`},
+		{`REPLACECHR(1, 'abcABC', NULL, 'x')`, `abcABC`},
+		{`REPLACECHR(1, NULL, 'a', 'x')`, `NULL`},
+	})
+}
+
+func TestREPLACESTR(t *testing.T) {
+	runStringTests(t, []stringTestCase{
+		{`REPLACESTR(1, 'Mr. Ng', 'Mr.', 'Mrs.', 'Ms.')`, `Ms. Ng`},
+		{`REPLACESTR(1, 'Mrs. Smith', 'Mr.', 'Mrs.', 'Ms.')`, `Ms. Smith`},
+		{`REPLACESTR(1, 'abc', 'a', 'ab', '*')`, `*bc`},
+		{`REPLACESTR(1, 'abc', 'ab', 'a', '*')`, `*c`},
+		{`REPLACESTR(0, 'MR. Ng', 'mr.', 'Mr.')`, `Mr. Ng`},
+		{`REPLACESTR(1, 'MR. Ng', 'mr.', 'Mr.')`, `MR. Ng`},
+		{`REPLACESTR(1, 'abc', 'ab', 'bc', '*')`, `*c`},
+		{`REPLACESTR(1, 'abc', 'bc', 'ab', '*')`, `*c`},
+		{`REPLACESTR(1, 'aaa', 'aa', 'b')`, `ba`},
+		{`REPLACESTR(1, 'a-b-c', '-', NULL)`, `abc`},
+		{`REPLACESTR(1, 'a-b-c', '-', '')`, `abc`},
+		{`REPLACESTR(1, 'a-b-c', NULL, '', '+')`, `a-b-c`},
+		{`REPLACESTR(1, NULL, '-', '+')`, `NULL`},
+	})
+}
+
+func TestREVERSE(t *testing.T) {
+	runStringTests(t, []stringTestCase{
+		{`REVERSE('abc')`, `cba`},
+		{`REVERSE('naïve')`, `evïan`},
+		{`REVERSE('')`, ``},
+		{`REVERSE(NULL)`, `NULL`},
+	})
+}
+
+func TestSUBSTR(t *testing.T) {
+	runStringTests(t, []stringTestCase{
+		{`SUBSTR('808-555-1234', 0, 3)`, `808`},
+		{`SUBSTR('808-555-1234', 1, 3)`, `808`},
+		{`SUBSTR('808-555-1234', 5, 8)`, `555-1234`},
+		{`SUBSTR('808-555-1234', 5)`, `555-1234`},
+		{`SUBSTR('808-555-1234', -8, 3)`, `555`},
+		{`SUBSTR('808-555-1234', -4)`, `1234`},
+		{`SUBSTR('808-555-1234', -12, 3)`, `808`},
+		{`SUBSTR('808-555-1234', -13, 3)`, ``},
+		{`SUBSTR('808-555-1234', 13)`, ``},
+		{`SUBSTR('808-555-1234', 1, 0)`, ``},
+		{`SUBSTR('808-555-1234', 1, -2)`, ``},
+		{`SUBSTR('808-555-1234', 1, 50)`, `808-555-1234`},
+		{`SUBSTR('808-555-1234', 1.5, 2.5)`, `08-`},
+		{`SUBSTR('naïve', 3, 1)`, `ï`},
+		{`SUBSTR(NULL, 1)`, `NULL`},
+		{`SUBSTR('abc', NULL)`, `NULL`},
+		{`SUBSTR('abc', 1, NULL)`, `NULL`},
+	})
+}
@@ -0,0 +1,246 @@
+// transformations evaluate the ports of an Expression transformation row by row
+
+package expression
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PortKind is whether a port is an input, output, input/output or variable port
+type PortKind int
+
+// Port kinds
+const (
+	PortInput       PortKind = iota // I, holds a value from the row
+	PortOutput                      // O, calculated by its expression and passed on
+	PortInputOutput                 // I/O, passes a value from the row on unchanged
+	PortVariable                    // V, calculated by its expression and kept for the next row
+)
+
+var portKindNames = map[PortKind]string{
+	PortInput:       "input",
+	PortOutput:      "output",
+	PortInputOutput: "input/output",
+	PortVariable:    "variable",
+}
+
+func (k PortKind) String() string {
+	return portKindNames[k]
+}
+
+// Port is a port of a transformation with its datatype and, for output and variable ports, its expression
+// Precision is the length of a string or binary, or the number of digits of a decimal, which has Scale digits after
+// the decimal point
+type Port struct {
+	Name       string
+	Kind       PortKind
+	Type       Type
+	Precision  int
+	Scale      int
+	Expression string
+}
+
+// Transformation evaluates the ports of an Expression transformation for each row in PowerCenter's order: input and
+// input/output ports first, then variable ports from top to bottom, then output ports
+// A variable port keeps its value from one row to the next, so a variable port that refers to one below it sees the
+// value from the previous row, e.g. v_PREV_KEY below v_IS_NEW_KEY := in_KEY <> v_PREV_KEY
+// Output ports can't be referred to by other ports
+// Values are converted to the datatype of each port as they are read from the row and as ports are calculated, so a
+// string is truncated to the port's precision and an integer which overflows is a row error
+// Like a Stream, a Transformation is modified by each row so it must only be used from one goroutine at a time
+type Transformation struct {
+	ports     []Port
+	streams   []*Stream // the program of each output and variable port, nil for input and input/output ports
+	variables Row       // the values of the variable ports after the last row
+}
+
+// NewTransformation compiles the expressions of the ports, using an Evaluator with the default Options
+func NewTransformation(ports []Port) (transformation *Transformation, err error) {
+	return defaultEvaluator.NewTransformation(ports)
+}
+
+// NewTransformation compiles the expressions of the ports
+// The ports must have unique names and only output and variable ports have expressions, which must only use functions
+// this library can evaluate
+// The value of LEAD can't read variable ports, since they are only calculated for the rows up to the current one
+func (e *Evaluator) NewTransformation(ports []Port) (transformation *Transformation, err error) {
+	var schema []Declaration
+	names := map[string]bool{}
+	variables := map[string]bool{}
+	for _, port := range ports {
+		if names[port.Name] {
+			err = fmt.Errorf("the port %s is declared more than once", port.Name)
+			return
+		}
+		names[port.Name] = true
+		variables[port.Name] = port.Kind == PortVariable
+
+		if port.Kind != PortOutput {
+			schema = append(schema, Declaration{Name: port.Name, Type: port.Type})
+		}
+	}
+
+	transformation = &Transformation{ports: append([]Port(nil), ports...), streams: make([]*Stream, len(ports))}
+	for i, port := range ports {
+		calculated := port.Kind == PortOutput || port.Kind == PortVariable
+		switch {
+		case calculated && strings.TrimSpace(port.Expression) == "":
+			err = fmt.Errorf("the %s port %s has no expression", port.Kind, port.Name)
+		case !calculated && port.Expression != "":
+			err = fmt.Errorf("the %s port %s can't have an expression", port.Kind, port.Name)
+		}
+		if err != nil {
+			transformation = nil
+			return
+		}
+		if !calculated {
+			continue
+		}
+
+		program, cErr := e.Compile(port.Expression, schema)
+		if cErr != nil {
+			err = fmt.Errorf("the port %s: %v", port.Name, cErr)
+			transformation = nil
+			return
+		}
+		// a port which can't be evaluated would fail on every row
+		if diagnostics := unimplemented(program.node); len(diagnostics) > 0 {
+			err = fmt.Errorf("the port %s: %s", port.Name, diagnostics[0].Message)
+			transformation = nil
+			return
+		}
+		if name := leadVariable(program.node, variables); name != "" {
+			err = fmt.Errorf("the port %s: LEAD can't read the variable port %s, which isn't calculated for the rows "+
+				"that follow", port.Name, name)
+			transformation = nil
+			return
+		}
+		transformation.streams[i] = program.Stream()
+	}
+	transformation.Reset()
+
+	return
+}
+
+// Ports returns the ports of the transformation
+func (t *Transformation) Ports() []Port {
+	return append([]Port(nil), t.ports...)
+}
+
+// Reset sets the variable ports back to their initial values and resets the window functions, as at the start of a
+// session
+func (t *Transformation) Reset() {
+	t.variables = Row{}
+	for i, port := range t.ports {
+		if port.Kind == PortVariable {
+			t.variables[port.Name] = initialValue(port.Type, port.Precision, port.Scale)
+		}
+		if t.streams[i] != nil {
+			t.streams[i].Reset()
+		}
+	}
+}
+
+// Next evaluates the ports for the next row of input port values and returns the values of the output and
+// input/output ports
+func (t *Transformation) Next(input Row) (output Row, err error) {
+	return t.evaluate(input, nil)
+}
+
+// Run evaluates the ports for each of the rows in order, as if they were passed to Next one at a time, except that
+// LEAD sees the rows which follow each row in rows
+func (t *Transformation) Run(inputs []Row) (outputs []Row, err error) {
+	outputs = make([]Row, len(inputs))
+	for i, input := range inputs {
+		if outputs[i], err = t.evaluate(input, inputs[i+1:]); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// leadVariable returns the name of a variable port read by the value of a call to LEAD in the node, or "" if there
+// isn't one
+func leadVariable(node Node, variables map[string]bool) string {
+	if node.Exp == "LEAD" && len(node.Args) > 0 {
+		if name := readVariable(node.Args[0], variables); name != "" {
+			return name
+		}
+	}
+	for _, arg := range node.Args {
+		if name := leadVariable(arg, variables); name != "" {
+			return name
+		}
+	}
+
+	return ""
+}
+
+// readVariable returns the name of a variable port the node reads, or "" if it doesn't read one
+func readVariable(node Node, variables map[string]bool) string {
+	if node.Exp == "IDENT" && variables[node.Name] {
+		return node.Name
+	}
+	for _, arg := range node.Args {
+		if name := readVariable(arg, variables); name != "" {
+			return name
+		}
+	}
+
+	return ""
+}
+
+func (t *Transformation) evaluate(input Row, following []Row) (output Row, err error) {
+	row := Row{}
+	for _, port := range t.ports {
+		switch port.Kind {
+		case PortInput, PortInputOutput:
+			if row[port.Name], err = port.convert(input[port.Name]); err != nil {
+				return
+			}
+		case PortVariable:
+			row[port.Name] = t.variables[port.Name]
+		}
+	}
+
+	for i, port := range t.ports {
+		if port.Kind != PortVariable {
+			continue
+		}
+		var v Value
+		if v, err = t.streams[i].evaluate(row, following); err != nil {
+			return
+		}
+		if row[port.Name], err = port.convert(v); err != nil {
+			return
+		}
+	}
+
+	output = Row{}
+	for i, port := range t.ports {
+		switch port.Kind {
+		case PortInputOutput:
+			output[port.Name] = row[port.Name]
+		case PortOutput:
+			var v Value
+			if v, err = t.streams[i].evaluate(row, following); err == nil {
+				output[port.Name], err = port.convert(v)
+			}
+			if err != nil {
+				output = nil
+				return
+			}
+		}
+	}
+
+	// the variables only keep their new values once the whole row has been evaluated
+	for _, port := range t.ports {
+		if port.Kind == PortVariable {
+			t.variables[port.Name] = row[port.Name]
+		}
+	}
+
+	return
+}
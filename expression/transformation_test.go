@@ -0,0 +1,130 @@
+package expression
+
+import (
+	"strings"
+	"testing"
+)
+
+// a running count of the rows with each key, where the rows are sorted by key
+var countPorts = []Port{
+	{Name: "in_KEY", Kind: PortInputOutput, Type: TypeString, Precision: 10},
+	{Name: "in_AMOUNT", Kind: PortInput, Type: TypeInteger},
+	{Name: "v_IS_NEW_KEY", Kind: PortVariable, Type: TypeInteger, Expression: "IIF(in_KEY = v_PREV_KEY, 0, 1)"},
+	{Name: "v_COUNT", Kind: PortVariable, Type: TypeInteger, Expression: "IIF(v_IS_NEW_KEY = 1, 1, v_COUNT + 1)"},
+	{Name: "v_PREV_KEY", Kind: PortVariable, Type: TypeString, Precision: 10, Expression: "in_KEY"},
+	{Name: "o_COUNT", Kind: PortOutput, Type: TypeInteger, Expression: "v_COUNT"},
+	{Name: "o_TOTAL", Kind: PortOutput, Type: TypeDouble, Expression: "CUME(in_AMOUNT)"},
+	{Name: "o_NEXT", Kind: PortOutput, Type: TypeInteger, Expression: "LEAD(in_AMOUNT, 1, 0)"},
+}
+
+var countRows = []Row{
+	{"in_KEY": NewString("a"), "in_AMOUNT": NewInteger(1)},
+	{"in_KEY": NewString("a"), "in_AMOUNT": NewInteger(2)},
+	{"in_KEY": NewString("b"), "in_AMOUNT": NewInteger(3)},
+	{"in_KEY": NewString("c"), "in_AMOUNT": Value{}},
+	{"in_KEY": NewString("c"), "in_AMOUNT": NewInteger(5)},
+}
+
+// outputString formats the values of the ports in the row separated by commas
+func outputString(row Row, ports ...string) string {
+	values := make([]Value, len(ports))
+	for i, port := range ports {
+		values[i] = row[port]
+	}
+
+	return valuesString(values)
+}
+
+func TestTransformation(t *testing.T) {
+	transformation, err := NewTransformation(countPorts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expect := []string{"a,1,1,2", "a,2,3,3", "b,1,6,NULL", "c,1,6,5", "c,2,11,0"}
+	for run := 0; run < 2; run++ {
+		outputs, err := transformation.Run(countRows)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, output := range outputs {
+			if len(output) != 4 {
+				t.Errorf("Row: %d\nExpected 4 output ports, got %v", i, output)
+			}
+			if result := outputString(output, "in_KEY", "o_COUNT", "o_TOTAL", "o_NEXT"); result != expect[i] {
+				t.Errorf("Row: %d\nExpected: `%s`, got `%s`", i, expect[i], result)
+			}
+		}
+		// the next run starts again from the initial values
+		transformation.Reset()
+	}
+}
+
+func TestTransformationNext(t *testing.T) {
+	transformation, err := NewTransformation([]Port{
+		{Name: "in_NAME", Kind: PortInput, Type: TypeString},
+		{Name: "v_NAMES", Kind: PortVariable, Type: TypeString, Expression: "CONCAT(v_NAMES, in_NAME)"},
+		{Name: "v_DATE", Kind: PortVariable, Type: TypeDate, Expression: "v_DATE"},
+		{Name: "o_NAMES", Kind: PortOutput, Type: TypeString, Expression: "CONCAT(v_NAMES, TO_CHAR(v_DATE, 'YYYY'))"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// variable ports start as empty strings and 1/1/1753
+	testCases := []struct {
+		name   string
+		expect string
+	}{
+		{"x", "x1753"},
+		{"y", "xy1753"},
+	}
+	for _, tc := range testCases {
+		output, err := transformation.Next(Row{"in_NAME": NewString(tc.name)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result := output["o_NAMES"].String(); result != tc.expect {
+			t.Errorf("Input: %s\nExpected: `%s`, got `%s`", tc.name, tc.expect, result)
+		}
+	}
+}
+
+func TestTransformationErrors(t *testing.T) {
+	testCases := [][]Port{
+		{{Name: "in_A", Kind: PortInput, Type: TypeString}, {Name: "in_A", Kind: PortInput, Type: TypeString}},
+		{{Name: "o_A", Kind: PortOutput, Type: TypeString}},
+		{{Name: "in_A", Kind: PortInput, Type: TypeString, Expression: "'a'"}},
+		{{Name: "o_A", Kind: PortOutput, Type: TypeString, Expression: "o_B"}, {Name: "o_B", Kind: PortOutput,
+			Type: TypeString, Expression: "'b'"}},
+		{{Name: "v_A", Kind: PortVariable, Type: TypeInteger, Expression: "1 +"}},
+		{{Name: "v_A", Kind: PortVariable, Type: TypeInteger, Expression: "METAPHONE('a')"}},
+	}
+
+	for _, ports := range testCases {
+		if _, err := NewTransformation(ports); err == nil {
+			t.Errorf("Ports: %v\nExpected an error", ports)
+		}
+	}
+
+	// LEAD only sees the input ports of the rows which follow, so it can't read a variable port
+	_, err := NewTransformation([]Port{
+		{Name: "in_A", Kind: PortInput, Type: TypeInteger},
+		{Name: "v_X", Kind: PortVariable, Type: TypeInteger, Expression: "in_A * 10"},
+		{Name: "o_L", Kind: PortOutput, Type: TypeInteger, Expression: "LEAD(v_X, 1, -1)"},
+	})
+	if expect := "LEAD can't read the variable port v_X"; err == nil || !strings.Contains(err.Error(), expect) {
+		t.Errorf("Expected an error containing `%s`, got `%v`", expect, err)
+	}
+
+	transformation, err := NewTransformation([]Port{
+		{Name: "in_A", Kind: PortInput, Type: TypeInteger},
+		{Name: "o_A", Kind: PortOutput, Type: TypeInteger, Expression: "10 / in_A"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := transformation.Next(Row{"in_A": NewInteger(0)}); err == nil {
+		t.Error("Expected a row error dividing by zero")
+	}
+}
@@ -0,0 +1,113 @@
+// validation checks an expression without evaluating it, like the Designer's Validate button
+
+package expression
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Severity of a Diagnostic
+type Severity int
+
+// Severities
+const (
+	SeverityError   Severity = iota // the expression is invalid
+	SeverityWarning                 // the expression is valid but probably doesn't do what was intended
+)
+
+func (s Severity) String() string {
+	if s == SeverityWarning {
+		return "warning"
+	}
+
+	return "error"
+}
+
+// Declaration is a port, variable or parameter that an expression can refer to, with its datatype
+// Unlike a Variable it has no value; the expression is only checked
+type Declaration struct {
+	Name string
+	Type Type
+}
+
+// Diagnostic is a problem found in an expression
+type Diagnostic struct {
+	Severity Severity
+	Code     string // one of the Code constants
+	Message  string
+	Span     Span // where the problem is in the input
+}
+
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%d:%d: %s: %s [%s]", d.Span.Start.Line, d.Span.Start.Column, d.Severity, d.Message, d.Code)
+}
+
+// Validate lexes and parses the input, resolving identifiers against the declarations, and returns every problem found
+// rather than stopping at the first; the expression is valid if none of the diagnostics are errors
+// Diagnostics are ordered by their position in the input
+func Validate(input string, declarations []Declaration) (diagnostics []Diagnostic) {
+	node, errs, warnings := parseAll([]byte(input), nil, declarations)
+
+	for _, err := range errs {
+		diagnostics = append(diagnostics, newDiagnostic(SeverityError, err))
+	}
+	for _, warning := range warnings {
+		diagnostics = append(diagnostics, newDiagnostic(SeverityWarning, warning))
+	}
+
+	// a syntax error before the end leaves the AST incomplete so it isn't worth walking
+	if len(errs) == 0 {
+		diagnostics = append(diagnostics, unimplemented(node)...)
+	}
+
+	sort.SliceStable(diagnostics, func(i, j int) bool {
+		a, b := diagnostics[i].Span.Start, diagnostics[j].Span.Start
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+
+	return
+}
+
+// newDiagnostic converts an error found while parsing to a Diagnostic
+func newDiagnostic(severity Severity, err *SyntaxError) Diagnostic {
+	return Diagnostic{Severity: severity, Code: err.Code, Message: err.Message, Span: err.Span}
+}
+
+// unimplemented warns about calls to functions, and uses of operators, which PowerCenter accepts but this library
+// can't evaluate
+func unimplemented(node Node) (diagnostics []Diagnostic) {
+	switch node.Exp {
+	case "VALUE", "IDENT":
+		return
+	}
+
+	_, isFunction := functions[node.Exp]
+	_, isOperator := precedence[node.Exp]
+	if isFunction || isOperator || node.Exp == "NOT" {
+		_, eager := fns[node.Exp]
+		_, lazy := lazyFns[node.Exp]
+		_, withEnv := envFns[node.Exp]
+		if !eager && !lazy && !withEnv {
+			kind := "function"
+			if !isFunction {
+				kind = "operator"
+			}
+			diagnostics = append(diagnostics, Diagnostic{
+				Severity: SeverityWarning,
+				Code:     CodeNotImplemented,
+				Message:  fmt.Sprintf("the %s %s hasn't been implemented by this library", kind, node.Exp),
+				Span:     node.Span,
+			})
+		}
+	}
+
+	for _, arg := range node.Args {
+		diagnostics = append(diagnostics, unimplemented(arg)...)
+	}
+
+	return
+}
@@ -0,0 +1,100 @@
+package expression
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	declarations := []Declaration{
+		{Name: "in_NAME", Type: TypeString},
+		{Name: "in_AMOUNT", Type: TypeDecimal},
+		{Name: "in_DATE", Type: TypeDate},
+		{Name: "$$RUN_ID", Type: TypeInteger},
+	}
+	testCases := []struct {
+		input  string
+		expect []string // Diagnostic.String() of each diagnostic
+	}{
+		{"CONCAT(in_NAME, 'x')", nil},
+		{"IIF(in_AMOUNT > 0, in_AMOUNT, -in_AMOUNT) + $$RUN_ID", nil},
+		{"in_MISSING", []string{"1:1: error: the identifier 'in_MISSING' was not found [unknown-identifier]"}},
+		{
+			"CONCAT(in_X, in_Y)",
+			[]string{
+				"1:8: error: the identifier 'in_X' was not found [unknown-identifier]",
+				"1:14: error: the identifier 'in_Y' was not found [unknown-identifier]",
+			},
+		},
+		{
+			"FOO(in_NAME) || ABS(1, 2)",
+			[]string{
+				"1:1: error: the function FOO does not exist [unknown-function]",
+				"1:17: error: ABS expects 1 argument, got 2 [argument-count]",
+			},
+		},
+		{"ABS(in_DATE)", []string{"1:5: error: ABS expects numeric_value to be a number, got DATE/TIME [argument-type]"}},
+		{
+			"CONCAT(1 +, in_X)",
+			[]string{
+				"1:11: error: found unexpected ',' [syntax]",
+				"1:13: error: the identifier 'in_X' was not found [unknown-identifier]",
+			},
+		},
+		{
+			"CONCAT('a' 'b', ABS(1, 2))",
+			[]string{
+				"1:12: error: expected ',' or ')' in the arguments to CONCAT but found ''b'' [syntax]",
+				"1:17: error: ABS expects 1 argument, got 2 [argument-count]",
+			},
+		},
+		{"ABS(1", []string{"1:5: error: reached the end of the expression [syntax]"}},
+		{"'abc", []string{"1:1: error: unclosed string [syntax]"}},
+		{"in_NAME = NULL", []string{"1:1: warning: comparing with NULL using = is always NULL, use ISNULL instead [null-comparison]"}},
+		{"METAPHONE(in_NAME)", []string{"1:1: warning: the function METAPHONE hasn't been implemented by this library [not-implemented]"}},
+	}
+
+	for _, tc := range testCases {
+		diagnostics := Validate(tc.input, declarations)
+		var result []string
+		for _, d := range diagnostics {
+			result = append(result, d.String())
+		}
+		if strings.Join(result, "\n") != strings.Join(tc.expect, "\n") {
+			t.Errorf("Input: %s\nExpected:\n%s\ngot:\n%s", tc.input, strings.Join(tc.expect, "\n"), strings.Join(result, "\n"))
+		}
+	}
+}
+
+func TestValidateSpans(t *testing.T) {
+	diagnostics := Validate("IIF(a,\n  ABS(b, c))", nil)
+	if len(diagnostics) != 4 {
+		t.Fatalf("Expected 4 diagnostics, got %v", diagnostics)
+	}
+	// ordered by position, so the call to ABS comes before its arguments
+	abs := diagnostics[1]
+	if abs.Code != CodeArgumentCount {
+		t.Errorf("Expected `%s`, got `%s`", CodeArgumentCount, abs.Code)
+	}
+	expect := Span{Position{2, 3}, Position{2, 11}}
+	if abs.Span != expect {
+		t.Errorf("Expected `%v`, got `%v`", expect, abs.Span)
+	}
+}
+
+func TestValidateOperators(t *testing.T) {
+	// every operator can be evaluated, so a valid expression using it has no diagnostics
+	inputs := []string{"NOT 1", "-1"}
+	for op := range precedence {
+		inputs = append(inputs, fmt.Sprintf("'1' %s '2'", op))
+	}
+
+	for _, input := range inputs {
+		for _, d := range Validate(input, nil) {
+			if d.Code == CodeNotImplemented {
+				t.Errorf("Input: %s\nUnexpected diagnostic: %s", input, d)
+			}
+		}
+	}
+}
@@ -0,0 +1,410 @@
+// values are the typed data that expressions operate on
+
+package expression
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Type is an Informatica transformation datatype
+type Type int
+
+// Transformation datatypes
+// https://docs.informatica.com/data-integration/powercenter/10-4-0/designer-guide/datatype-reference/transformation-datatypes.html
+const (
+	TypeNull Type = iota // the type of a NULL literal, NULLs of other types keep their type
+	TypeString
+	TypeInteger // 32 bit
+	TypeBigint  // 64 bit
+	TypeDecimal
+	TypeDouble
+	TypeDate // date/time with nanosecond precision
+	TypeBinary
+)
+
+var typeNames = map[Type]string{
+	TypeNull:    "NULL",
+	TypeString:  "STRING",
+	TypeInteger: "INTEGER",
+	TypeBigint:  "BIGINT",
+	TypeDecimal: "DECIMAL",
+	TypeDouble:  "DOUBLE",
+	TypeDate:    "DATE/TIME",
+	TypeBinary:  "BINARY",
+}
+
+func (t Type) String() string {
+	if name, ok := typeNames[t]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("Type(%d)", int(t))
+}
+
+// IsNumeric checks if the type is one of the numeric datatypes
+func (t Type) IsNumeric() bool {
+	switch t {
+	case TypeInteger, TypeBigint, TypeDecimal, TypeDouble:
+		return true
+	}
+
+	return false
+}
+
+// ParseType converts a datatype name, as used in Variable.T, to a Type
+func ParseType(name string) (t Type, err error) {
+	switch strings.ToUpper(strings.TrimSpace(name)) {
+	case "NULL":
+		t = TypeNull
+	case "STRING", "NSTRING", "TEXT", "NTEXT", "CHAR", "VARCHAR":
+		t = TypeString
+	case "INTEGER", "SMALLINT":
+		t = TypeInteger
+	case "BIGINT":
+		t = TypeBigint
+	case "DECIMAL":
+		t = TypeDecimal
+	case "DOUBLE", "REAL", "FLOAT":
+		t = TypeDouble
+	case "DATE", "DATE/TIME", "DATETIME", "TIMESTAMP":
+		t = TypeDate
+	case "BINARY":
+		t = TypeBinary
+	default:
+		err = fmt.Errorf("unknown datatype '%s'", name)
+	}
+
+	return
+}
+
+// DefaultDateFormat is the format PowerCenter uses to convert between dates and strings when no format is given
+const DefaultDateFormat = "MM/DD/YYYY HH24:MI:SS.US"
+
+// Value is a typed value; the zero Value is NULL
+type Value struct {
+	typ       Type
+	null      bool // a NULL of a specific type, e.g. the result of ABS(NULL)
+	s         string
+	i         int64
+	f         float64
+	t         time.Time
+	b         []byte
+	d         *big.Int // the unscaled digits of a decimal, so 12.50 is 1250 with a scale of 2
+	precision int
+	scale     int
+}
+
+// NewNull returns a NULL of the given type
+func NewNull(t Type) Value {
+	return Value{typ: t, null: t != TypeNull}
+}
+
+// NewString returns a string value
+func NewString(s string) Value {
+	return Value{typ: TypeString, s: s}
+}
+
+// NewInteger returns a 32 bit integer value
+func NewInteger(i int32) Value {
+	return Value{typ: TypeInteger, i: int64(i)}
+}
+
+// NewBigint returns a 64 bit integer value
+func NewBigint(i int64) Value {
+	return Value{typ: TypeBigint, i: i}
+}
+
+// NewDecimal returns a decimal value with the given precision and scale
+// The digits of f are rounded to the scale; use ParseDecimal for decimals with more digits than a float holds
+func NewDecimal(f float64, precision int, scale int) Value {
+	return Value{typ: TypeDecimal, f: f, d: roundRat(NewDouble(f).rat(), scale, true), precision: precision, scale: scale}
+}
+
+// NewDouble returns a double precision floating point value
+func NewDouble(f float64) Value {
+	return Value{typ: TypeDouble, f: f}
+}
+
+// NewDate returns a date/time value
+// PowerCenter dates don't have a time zone, so only the wall clock time of t is kept
+func NewDate(t time.Time) Value {
+	if t.Location() != time.UTC {
+		t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+	}
+
+	return Value{typ: TypeDate, t: t}
+}
+
+// NewBinary returns a binary value
+func NewBinary(b []byte) Value {
+	return Value{typ: TypeBinary, b: b}
+}
+
+// Type of the value
+func (v Value) Type() Type {
+	return v.typ
+}
+
+// IsNull checks if the value is NULL
+func (v Value) IsNull() bool {
+	return v.typ == TypeNull || v.null
+}
+
+// Precision of a decimal value
+func (v Value) Precision() int {
+	return v.precision
+}
+
+// Scale of a decimal value
+func (v Value) Scale() int {
+	return v.scale
+}
+
+// Str returns the value of a string
+func (v Value) Str() string {
+	return v.s
+}
+
+// Int returns the value of an integer or bigint, numbers of other types are truncated
+func (v Value) Int() int64 {
+	switch v.typ {
+	case TypeDecimal, TypeDouble:
+		return int64(v.f)
+	}
+
+	return v.i
+}
+
+// Float returns the value of any numeric type as a float
+func (v Value) Float() float64 {
+	switch v.typ {
+	case TypeInteger, TypeBigint:
+		return float64(v.i)
+	}
+
+	return v.f
+}
+
+// Time returns the value of a date
+func (v Value) Time() time.Time {
+	return v.t
+}
+
+// Bytes returns the value of a binary
+func (v Value) Bytes() []byte {
+	return v.b
+}
+
+// String renders the value the way PowerCenter writes it to a string port
+func (v Value) String() string {
+	if v.IsNull() {
+		return "NULL"
+	}
+
+	switch v.typ {
+	case TypeString:
+		return v.s
+	case TypeInteger, TypeBigint:
+		return strconv.FormatInt(v.i, 10)
+	case TypeDecimal:
+		return formatDecimal(v.d, v.scale)
+	case TypeDouble:
+		return formatDouble(v.f)
+	case TypeDate:
+		return formatDate(v.t, DefaultDateFormat)
+	case TypeBinary:
+		return strings.ToUpper(hex.EncodeToString(v.b))
+	}
+
+	return ""
+}
+
+// formatDouble renders a double with up to 15 significant digits, switching to exponent notation for very large or
+// small numbers (e.g. 1.08427649682088e+19)
+func formatDouble(f float64) string {
+	if math.IsInf(f, 0) || math.IsNaN(f) {
+		return strconv.FormatFloat(f, 'g', -1, 64)
+	}
+
+	// round to 15 significant digits first so that float noise (e.g. 0.1 + 0.2) isn't shown
+	rounded, _ := strconv.ParseFloat(strconv.FormatFloat(f, 'e', 14, 64), 64)
+	if rounded == 0 {
+		return "0"
+	}
+
+	exp := int(math.Floor(math.Log10(math.Abs(rounded))))
+	if exp < -15 || exp >= 15 {
+		mantissa, exponent := splitExponent(strconv.FormatFloat(rounded, 'e', -1, 64))
+		return mantissa + "e" + exponent
+	}
+
+	return strconv.FormatFloat(rounded, 'f', -1, 64)
+}
+
+// splitExponent splits a number formatted with 'e' into its mantissa and exponent
+func splitExponent(s string) (mantissa string, exponent string) {
+	parts := strings.SplitN(s, "e", 2)
+	mantissa = parts[0]
+	if len(parts) > 1 {
+		exponent = parts[1]
+	}
+
+	return
+}
+
+// formatDate renders a date in an Informatica date format, which is assumed to be valid
+func formatDate(t time.Time, format string) string {
+	s, _ := formatDateWith(t, format)
+	return s
+}
+
+// parseNumber converts the text of a number to the narrowest type which holds it:
+// an Integer, then Bigint, then a Decimal with the precision and scale of the text
+func parseNumber(text string) (v Value, err error) {
+	text = strings.TrimSpace(text)
+
+	if i, iErr := strconv.ParseInt(text, 10, 64); iErr == nil {
+		if i >= math.MinInt32 && i <= math.MaxInt32 {
+			v = NewInteger(int32(i))
+		} else {
+			v = NewBigint(i)
+		}
+		return
+	}
+
+	f, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		err = fmt.Errorf("'%s' is not a number", text)
+		return
+	}
+
+	// exponents (e.g. 1e10) and infinities don't have a sensible scale so they are doubles
+	if strings.ContainsAny(text, "eEiInN") {
+		v = NewDouble(f)
+		return
+	}
+
+	digits := strings.TrimLeft(text, "+-")
+	scale := 0
+	if dot := strings.Index(digits, "."); dot >= 0 {
+		scale = len(digits) - dot - 1
+		digits = digits[:dot] + digits[dot+1:]
+	}
+	precision := len(strings.TrimLeft(digits, "0"))
+	if precision < scale {
+		precision = scale
+	}
+	if precision > maxDecimalPrecision {
+		v = NewDouble(f)
+		return
+	}
+	r, _ := new(big.Rat).SetString(text)
+	v, err = exactDecimal(r, scale)
+
+	return
+}
+
+// variableValue converts the string value of a Variable to a Value of the Variable's type
+// The type NUMBER can be used for any number, the narrowest numeric type is chosen based on the value
+// A type with a precision, e.g. string(10) or decimal(12,2), converts the value like a port with that datatype
+func variableValue(v Variable) (value Value, err error) {
+	if strings.ToUpper(v.T) == "NUMBER" {
+		return parseNumber(v.V)
+	}
+
+	t, precision, scale, err := ParseDatatype(v.T)
+	if err != nil {
+		return
+	}
+	if value, err = typedValue(v, t); err != nil || precision == 0 {
+		return
+	}
+	value, err = Port{Name: v.N, Type: t, Precision: precision, Scale: scale}.convert(value)
+
+	return
+}
+
+// typedValue converts the string value of a Variable to a Value of the type
+func typedValue(v Variable, t Type) (value Value, err error) {
+	switch t {
+	case TypeNull:
+		value = Value{}
+	case TypeString:
+		value = NewString(v.V)
+	case TypeInteger, TypeBigint, TypeDecimal, TypeDouble:
+		var n Value
+		n, err = parseNumber(v.V)
+		if err != nil {
+			return
+		}
+		value, err = convertNumber(n, t)
+	case TypeDate:
+		var d time.Time
+		d, err = parseDefaultDate(v.V)
+		value = NewDate(d)
+	case TypeBinary:
+		// binaries are written in hex, the same as Value.String renders them, with an optional 0x prefix
+		var b []byte
+		b, err = hex.DecodeString(strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(v.V), "0x"), "0X"))
+		if err != nil {
+			err = fmt.Errorf("the BINARY value of %s must be hex digits: %v", v.N, err)
+			return
+		}
+		value = NewBinary(b)
+	}
+
+	return
+}
+
+// convertNumber converts a number to another numeric type
+func convertNumber(v Value, t Type) (result Value, err error) {
+	if v.IsNull() {
+		result = NewNull(t)
+		return
+	}
+
+	switch t {
+	case TypeInteger:
+		f := math.Trunc(v.Float())
+		if f < math.MinInt32 || f > math.MaxInt32 {
+			err = fmt.Errorf("%s is out of range for an integer", v)
+			return
+		}
+		result = NewInteger(int32(f))
+	case TypeBigint:
+		if v.typ == TypeInteger || v.typ == TypeBigint {
+			result = NewBigint(v.i)
+			return
+		}
+		f := math.Trunc(v.f)
+		if f < math.MinInt64 || f >= math.MaxInt64 {
+			err = fmt.Errorf("%s is out of range for a bigint", v)
+			return
+		}
+		result = NewBigint(int64(f))
+	case TypeDecimal:
+		switch v.typ {
+		case TypeDecimal:
+			result = v
+		case TypeInteger, TypeBigint:
+			result, err = exactDecimal(v.rat(), 0)
+		default:
+			result, err = parseNumber(strconv.FormatFloat(v.f, 'f', -1, 64))
+			if err == nil && result.typ != TypeDecimal {
+				result = NewDecimal(result.Float(), maxDecimalPrecision, 0)
+			}
+		}
+	case TypeDouble:
+		result = NewDouble(v.Float())
+	default:
+		err = fmt.Errorf("cannot convert a number to %s", t)
+	}
+
+	return
+}
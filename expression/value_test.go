@@ -0,0 +1,137 @@
+package expression
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestValueString(t *testing.T) {
+	testCases := []struct {
+		value  Value
+		expect string
+	}{
+		{Value{}, `NULL`},
+		{NewNull(TypeString), `NULL`},
+		{NewString("abc"), `abc`},
+		{NewInteger(-42), `-42`},
+		{NewBigint(math.MaxInt64), `9223372036854775807`},
+		{NewDecimal(1.1, 3, 2), `1.10`},
+		{NewDecimal(0.1+0.2, 2, 1), `0.3`},
+		{NewDouble(0.1 + 0.2), `0.3`},
+		{NewDouble(-15.62567), `-15.62567`},
+		{NewDouble(10842764968208837340), `1.08427649682088e+19`},
+		{NewDouble(0), `0`},
+		{NewDate(time.Date(2020, 1, 31, 23, 59, 59, 123456789, time.UTC)), `01/31/2020 23:59:59.123456`},
+		{NewBinary([]byte{0xab, 0x01}), `AB01`},
+	}
+
+	for _, tc := range testCases {
+		if tc.value.String() != tc.expect {
+			t.Errorf("Expected: `%s`, got `%s`", tc.expect, tc.value.String())
+		}
+	}
+}
+
+func TestParseNumber(t *testing.T) {
+	testCases := []struct {
+		input     string
+		expect    Type
+		precision int
+		scale     int
+	}{
+		{`1`, TypeInteger, 0, 0},
+		{`-2147483648`, TypeInteger, 0, 0},
+		{`2147483648`, TypeBigint, 0, 0},
+		{`1.50`, TypeDecimal, 3, 2},
+		{`0.001`, TypeDecimal, 3, 3},
+		{`-12.5`, TypeDecimal, 3, 1},
+		{`1e3`, TypeDouble, 0, 0},
+		{`99999999999999999999`, TypeDecimal, 20, 0},
+		{`999999999999999999999999999999`, TypeDecimal, 30, 0},
+		{`9999999999999999999999999999999999999999`, TypeDouble, 0, 0},
+	}
+
+	for _, tc := range testCases {
+		v, err := parseNumber(tc.input)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+
+		if v.Type() != tc.expect || v.Precision() != tc.precision || v.Scale() != tc.scale {
+			t.Errorf("Input: %s\nExpected %s(%d, %d), got %s(%d, %d)",
+				tc.input, tc.expect, tc.precision, tc.scale, v.Type(), v.Precision(), v.Scale())
+		}
+	}
+
+	if _, err := parseNumber(`abc`); err == nil {
+		t.Error("Expected an error for a string which isn't a number")
+	}
+}
+
+func TestVariableValue(t *testing.T) {
+	testCases := []struct {
+		variable Variable
+		expect   Type
+		str      string
+	}{
+		{Variable{"a", "STRING", "x"}, TypeString, `x`},
+		{Variable{"a", "NUMBER", "2"}, TypeInteger, `2`},
+		{Variable{"a", "NUMBER", "2.5"}, TypeDecimal, `2.5`},
+		{Variable{"a", "integer", "7"}, TypeInteger, `7`},
+		{Variable{"a", "BIGINT", "7"}, TypeBigint, `7`},
+		{Variable{"a", "DOUBLE", "7"}, TypeDouble, `7`},
+		{Variable{"a", "DECIMAL", "7.25"}, TypeDecimal, `7.25`},
+		{Variable{"a", "DATE", "01/31/2020 23:59:59"}, TypeDate, `01/31/2020 23:59:59.000000`},
+		{Variable{"a", "NULL", ""}, TypeNull, `NULL`},
+	}
+
+	for _, tc := range testCases {
+		v, err := variableValue(tc.variable)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+
+		if v.Type() != tc.expect || v.String() != tc.str {
+			t.Errorf("Variable: %+v\nExpected %s `%s`, got %s `%s`", tc.variable, tc.expect, tc.str, v.Type(), v.String())
+		}
+	}
+
+	if _, err := variableValue(Variable{"a", "INTEGER", "abc"}); err == nil {
+		t.Error("Expected an error for an INTEGER which isn't a number")
+	}
+}
+
+func TestEvaluateTypes(t *testing.T) {
+	testCases := []struct {
+		input  string
+		expect Type
+	}{
+		{`1 + 2`, TypeInteger},
+		{`2147483647 + 1`, TypeBigint},
+		{`-2147483648`, TypeInteger},
+		{`-2147483649`, TypeBigint},
+		{`-(2147483648)`, TypeBigint},
+		{`-9223372036854775808`, TypeBigint},
+		{`1 + 2.5`, TypeDouble}, // decimals are doubles unless high precision is enabled
+		{`7 / 2`, TypeDouble},
+		{`1 < 2`, TypeInteger},
+		{`'a'`, TypeString},
+		{`ABS(NULL + 1)`, TypeInteger},
+		{`NULL`, TypeNull},
+	}
+
+	for _, tc := range testCases {
+		result, err := Evaluate(tc.input, nil)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+
+		if result.Type() != tc.expect {
+			t.Errorf("Input: %s\nExpected: %s, got %s", tc.input, tc.expect, result.Type())
+		}
+	}
+}
@@ -0,0 +1,302 @@
+// window functions depend on the rows before or after the current row, like ports of an Expression transformation
+// which keep running results
+
+package expression
+
+import (
+	"fmt"
+)
+
+// Stream evaluates a program for a sequence of rows in order, keeping the state of the window functions CUME,
+// MOVINGAVG, MOVINGSUM and LAG from one row to the next
+// Unlike a Program, a Stream is modified by each row so it must only be used from one goroutine at a time
+type Stream struct {
+	program *Program
+	windows map[Position]*window // the state of each call to a window function
+}
+
+// window is the state of a call to a window function
+type window struct {
+	values []Value // the value of the first argument for each row counted so far
+	total  Value   // the running total of CUME
+}
+
+// Stream returns a new Stream of rows for the program
+func (p *Program) Stream() *Stream {
+	return &Stream{program: p, windows: map[Position]*window{}}
+}
+
+// Next evaluates the program for the next row
+// LEAD can't see any rows after this one so it returns its default
+func (s *Stream) Next(row Row) (result Value, err error) {
+	return s.evaluate(row, nil)
+}
+
+// Run evaluates the program for each of the rows in order, as if they were passed to Next one at a time, except
+// that LEAD sees the rows which follow each row in rows
+func (s *Stream) Run(rows []Row) (results []Value, err error) {
+	results = make([]Value, len(rows))
+	for i, row := range rows {
+		if results[i], err = s.evaluate(row, rows[i+1:]); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// Reset forgets the rows seen so far so the stream starts again as if it were new
+func (s *Stream) Reset() {
+	s.windows = map[Position]*window{}
+}
+
+func (s *Stream) evaluate(row Row, following []Row) (result Value, err error) {
+	env := s.program.evaluator.newEnvironment(row)
+	env.stream, env.following = s, following
+	result, err = evaluateNode(s.program.node, env)
+
+	return
+}
+
+// windowState returns the state of a call to a window function, or an error if there is no stream of rows
+// The call is identified by where its first argument starts in the input, which no other call can share
+func windowState(fn string, env *environment, args []Node) (w *window, err error) {
+	if env.stream == nil {
+		err = fmt.Errorf("%s is a window function so it can only be evaluated with a Stream", fn)
+		return
+	}
+
+	w, found := env.stream.windows[args[0].Span.Start]
+	if !found {
+		w = &window{}
+		env.stream.windows[args[0].Span.Start] = w
+	}
+
+	return
+}
+
+// passesFilter evaluates the optional filter_condition at filterArg, which is passed when it is missing or TRUE
+func passesFilter(fn string, env *environment, args []Node, filterArg int) (passed bool, err error) {
+	if len(args) <= filterArg {
+		passed = true
+		return
+	}
+
+	filter, err := evaluateNode(args[filterArg], env)
+	if err != nil || filter.IsNull() {
+		return
+	}
+	passed, err = isTrue(fn, filter)
+
+	return
+}
+
+// cume returns the running total of the values so far, skipping NULLs and rows which don't pass the filter
+// It is NULL until there is a value to add up
+func cume(env *environment, args ...Node) (result Value, err error) {
+	w, err := windowState("CUME", env, args)
+	if err != nil {
+		return
+	}
+
+	passed, err := passesFilter("CUME", env, args, 1)
+	if err != nil {
+		return
+	}
+	if passed {
+		var v Value
+		if v, err = evaluateNode(args[0], env); err != nil {
+			return
+		}
+		if !v.IsNull() {
+			if v, err = toNumeric("CUME", v); err != nil {
+				return
+			}
+			if w.total.IsNull() {
+				w.total = v
+			} else if w.total, err = add(w.total, v); err != nil {
+				return
+			}
+		}
+	}
+
+	if w.total.IsNull() {
+		result = NewNull(TypeDouble)
+	} else {
+		result = NewDouble(w.total.Float())
+	}
+
+	return
+}
+
+func movingAvg(env *environment, args ...Node) (result Value, err error) {
+	return moving("MOVINGAVG", env, args, true)
+}
+
+func movingSum(env *environment, args ...Node) (result Value, err error) {
+	return moving("MOVINGSUM", env, args, false)
+}
+
+// moving returns the sum or average of the values of the last rowset rows which pass the filter, skipping NULLs
+// The result is NULL until rowset rows have been seen, and when all of the values in the rowset are NULL
+// A row where the rowset is NULL isn't counted
+func moving(fn string, env *environment, args []Node, average bool) (result Value, err error) {
+	result = NewNull(TypeDouble)
+	w, err := windowState(fn, env, args)
+	if err != nil {
+		return
+	}
+
+	// the rowset is checked first so that no value is kept without knowing how many of them to keep
+	rowsetValue, err := evaluateNode(args[1], env)
+	if err != nil || rowsetValue.IsNull() {
+		return
+	}
+	rowset, err := toPosition(fn, rowsetValue)
+	if err != nil {
+		return
+	}
+	if rowset < 1 {
+		err = &RowError{Function: fn, Message: fmt.Sprintf("the rowset must be greater than 0, got %d", rowset)}
+		return
+	}
+
+	passed, err := passesFilter(fn, env, args, 2)
+	if err != nil {
+		return
+	}
+	if passed {
+		var v Value
+		if v, err = evaluateNode(args[0], env); err != nil {
+			return
+		}
+		if !v.IsNull() {
+			if v, err = toNumeric(fn, v); err != nil {
+				return
+			}
+		}
+		w.values = append(w.values, v)
+	}
+
+	// only the values in the rowset are kept
+	if len(w.values) > rowset {
+		w.values = w.values[len(w.values)-rowset:]
+	}
+	if len(w.values) < rowset {
+		return
+	}
+
+	var total Value
+	n := 0
+	for _, v := range w.values {
+		if v.IsNull() {
+			continue
+		}
+		if n++; n == 1 {
+			total = v
+		} else if total, err = add(total, v); err != nil {
+			return
+		}
+	}
+	if n == 0 {
+		return
+	}
+	if average {
+		result = NewDouble(total.Float() / float64(n))
+	} else {
+		result = NewDouble(total.Float())
+	}
+
+	return
+}
+
+// lag returns the value from offset rows before the current row, 1 by default, or default (NULL if it is omitted)
+// when there aren't that many rows before it
+// Only the last offset+1 values are kept, so an offset which grows from one row to the next can't reach values which
+// were already dropped
+func lag(env *environment, args ...Node) (result Value, err error) {
+	w, err := windowState("LAG", env, args)
+	if err != nil {
+		return
+	}
+
+	v, err := evaluateNode(args[0], env)
+	if err != nil {
+		return
+	}
+	w.values = append(w.values, v)
+
+	offset, err := windowOffset("LAG", env, args)
+	if err != nil {
+		return
+	}
+	// only the values which can still be reached are kept
+	if len(w.values) > offset+1 {
+		w.values = w.values[len(w.values)-(offset+1):]
+	}
+	if len(w.values) == offset+1 {
+		result = w.values[0]
+		return
+	}
+	result, err = windowDefault(env, args)
+
+	return
+}
+
+// lead returns the value from offset rows after the current row, 1 by default, or default (NULL if it is omitted)
+// when there aren't that many rows after it
+// The value is evaluated with the ports of that row, so it can't contain other window functions
+func lead(env *environment, args ...Node) (result Value, err error) {
+	if env.stream == nil {
+		err = fmt.Errorf("LEAD is a window function so it can only be evaluated with a Stream")
+		return
+	}
+
+	offset, err := windowOffset("LEAD", env, args)
+	if err != nil {
+		return
+	}
+	if offset == 0 {
+		result, err = evaluateNode(args[0], env)
+		return
+	}
+	if offset <= len(env.following) {
+		result, err = evaluateNode(args[0], &environment{evaluator: env.evaluator, row: env.following[offset-1], now: env.now})
+		return
+	}
+	result, err = windowDefault(env, args)
+
+	return
+}
+
+// windowOffset returns the offset argument of LAG or LEAD, which is 1 when it is omitted or NULL
+func windowOffset(fn string, env *environment, args []Node) (offset int, err error) {
+	offset = 1
+	if len(args) < 2 {
+		return
+	}
+
+	v, err := evaluateNode(args[1], env)
+	if err != nil || v.IsNull() {
+		return
+	}
+	if offset, err = toPosition(fn, v); err != nil {
+		return
+	}
+	if offset < 0 {
+		err = &RowError{Function: fn, Message: fmt.Sprintf("the offset can't be negative, got %d", offset)}
+	}
+
+	return
+}
+
+// windowDefault returns the default argument of LAG or LEAD, or a NULL of the value's type when it is omitted
+func windowDefault(env *environment, args []Node) (result Value, err error) {
+	if len(args) < 3 {
+		result = NewNull(resultType(args[0]))
+		return
+	}
+	result, err = evaluateNode(args[2], env)
+
+	return
+}
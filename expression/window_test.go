@@ -0,0 +1,162 @@
+package expression
+
+import (
+	"strings"
+	"testing"
+)
+
+var windowSchema = []Declaration{
+	{Name: "in_KEY", Type: TypeString},
+	{Name: "in_SALES", Type: TypeInteger},
+}
+
+var windowRows = []Row{
+	{"in_KEY": NewString("a"), "in_SALES": NewInteger(10)},
+	{"in_KEY": NewString("a"), "in_SALES": NewInteger(20)},
+	{"in_KEY": NewString("b"), "in_SALES": Value{}},
+	{"in_KEY": NewString("b"), "in_SALES": NewInteger(40)},
+	{"in_KEY": NewString("c"), "in_SALES": NewInteger(50)},
+}
+
+// valuesString formats the values separated by commas
+func valuesString(values []Value) string {
+	s := make([]string, len(values))
+	for i, v := range values {
+		s[i] = v.String()
+	}
+
+	return strings.Join(s, ",")
+}
+
+func TestStream(t *testing.T) {
+	testCases := []stringTestCase{
+		{`CUME(in_SALES)`, `10,30,30,70,120`},
+		{`CUME(in_SALES, in_KEY <> 'a')`, `NULL,NULL,NULL,40,90`},
+		{`MOVINGSUM(in_SALES, 2)`, `NULL,30,20,40,90`},
+		{`MOVINGSUM(in_SALES, 2, in_KEY = 'a' OR in_KEY = 'c')`, `NULL,30,30,30,70`},
+		{`MOVINGAVG(in_SALES, 3)`, `NULL,NULL,15,30,45`},
+		{`MOVINGAVG(in_SALES, 1)`, `10,20,NULL,40,50`},
+		{`LAG(in_SALES)`, `NULL,10,20,NULL,40`},
+		{`LAG(in_SALES, 2, 0)`, `0,0,10,20,NULL`},
+		{`LAG(in_KEY, 0)`, `a,a,b,b,c`},
+		{`IIF(in_KEY = LAG(in_KEY), 'same', 'new')`, `new,same,new,same,new`},
+		{`LEAD(in_SALES)`, `20,NULL,40,50,NULL`},
+		{`LEAD(in_KEY, 2, 'none')`, `b,b,c,none,none`},
+		{`CUME(in_SALES) - MOVINGSUM(in_SALES, 1)`, `0,10,NULL,30,70`},
+		{`LAG(CUME(in_SALES))`, `NULL,10,30,30,70`},
+	}
+
+	for _, tc := range testCases {
+		program, err := Compile(tc.input, windowSchema)
+		if err != nil {
+			t.Errorf("Input: %s\nUnexpected error: %v", tc.input, err)
+			continue
+		}
+		results, err := program.Stream().Run(windowRows)
+		if err != nil {
+			t.Errorf("Input: %s\nUnexpected error: %v", tc.input, err)
+			continue
+		}
+		if result := valuesString(results); result != tc.expect {
+			t.Errorf("Input: %s\nExpected: `%s`, got `%s`", tc.input, tc.expect, result)
+		}
+	}
+}
+
+func TestStreamNextAndReset(t *testing.T) {
+	program, err := Compile("CUME(in_SALES) + LEAD(in_SALES, 1, 0)", windowSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stream := program.Stream()
+
+	// Next doesn't see the rows which follow so LEAD returns its default
+	var results []Value
+	for _, row := range windowRows[:2] {
+		result, err := stream.Next(row)
+		if err != nil {
+			t.Fatal(err)
+		}
+		results = append(results, result)
+	}
+	if result := valuesString(results); result != "10,30" {
+		t.Errorf("Expected: `10,30`, got `%s`", result)
+	}
+
+	// the running total carries on across calls until the stream is reset
+	if results, err = stream.Run(windowRows[4:]); err != nil {
+		t.Fatal(err)
+	}
+	if result := valuesString(results); result != "80" {
+		t.Errorf("Expected: `80`, got `%s`", result)
+	}
+	stream.Reset()
+	if results, err = stream.Run(windowRows[4:]); err != nil {
+		t.Fatal(err)
+	}
+	if result := valuesString(results); result != "50" {
+		t.Errorf("Expected: `50` after Reset, got `%s`", result)
+	}
+}
+
+func TestStreamLagIsBounded(t *testing.T) {
+	program, err := Compile("LAG(in_SALES, 2)", windowSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stream := program.Stream()
+
+	// LAG only keeps the values it can still return, however many rows there are
+	for i := 0; i < 1000; i++ {
+		if _, err := stream.Next(windowRows[i%len(windowRows)]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, w := range stream.windows {
+		if len(w.values) != 3 {
+			t.Errorf("Expected 3 values to be kept, got %d", len(w.values))
+		}
+	}
+}
+
+func TestStreamMovingIsBounded(t *testing.T) {
+	program, err := Compile("MOVINGSUM(in_SALES, IIF(in_KEY = 'c', 2, NULL))", windowSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stream := program.Stream()
+
+	// rows where the rowset is NULL aren't kept, and the others are trimmed to the rowset
+	for i := 0; i < 1000; i++ {
+		if _, err := stream.Next(windowRows[i%len(windowRows)]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, w := range stream.windows {
+		if len(w.values) > 2 {
+			t.Errorf("Expected at most 2 values to be kept, got %d", len(w.values))
+		}
+	}
+}
+
+func TestStreamErrors(t *testing.T) {
+	for _, input := range []string{`MOVINGSUM(in_SALES, 0)`, `LAG(in_SALES, -1)`, `CUME(in_KEY)`} {
+		program, err := Compile(input, windowSchema)
+		if err != nil {
+			t.Errorf("Input: %s\nUnexpected error: %v", input, err)
+			continue
+		}
+		if _, err := program.Stream().Run(windowRows); err == nil {
+			t.Errorf("Input: %s\nExpected an error", input)
+		}
+	}
+
+	// window functions need a stream of rows
+	program, err := Compile("CUME(in_SALES)", windowSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := program.Run(windowRows[0]); err == nil {
+		t.Error("Expected an error running a window function on a single row")
+	}
+}